@@ -6,22 +6,29 @@ import (
 	"crypto/x509"
 	"fmt"
 	"gateway-service/internal"
+	"gateway-service/internal/certmanager"
 	"gateway-service/internal/config"
 	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
+	"gateway-service/internal/handlers"
+	"gateway-service/internal/logger"
+	"gateway-service/internal/metrics"
+	"gateway-service/internal/middlewares"
+	"gateway-service/internal/monitoring"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 
-	_ "gateway-service/internal/monitoring"
-
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
 
 func main() {
@@ -54,6 +61,32 @@ func main() {
 		"orchestrator", env.GetOrchestratorAddr(),
 	)
 
+	// 1b. Optional OpenTelemetry tracing — only wired when an OTLP
+	// endpoint is configured. Without it, monitoring.Start still works, it
+	// just hands back OTel's default no-op spans, the same "off unless
+	// configured" posture the Prometheus metrics provider has.
+	if endpoint := os.Getenv("GATEWAY_OTLP_ENDPOINT"); endpoint != "" {
+		protocol := envOr("GATEWAY_OTLP_PROTOCOL", "grpc")
+		shutdownTracing, err := monitoring.Init(ctx, monitoring.TracingConfig{
+			ServiceName:    "gateway-service",
+			ServiceVersion: envOr("GATEWAY_VERSION", "dev"),
+			OTLPEndpoint:   endpoint,
+			OTLPProtocol:   protocol,
+			Insecure:       envOr("GATEWAY_OTLP_INSECURE", "true") == "true",
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize OpenTelemetry tracing: %v", err)
+		}
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCancel()
+			if err := shutdownTracing(shutdownCtx); err != nil {
+				slog.Error("tracing shutdown error", "error", err)
+			}
+		}()
+		slog.Info("OpenTelemetry tracing: enabled", "endpoint", endpoint, "protocol", protocol)
+	}
+
 	// 2. Load bundle from CUE file
 	bundleService, err := config.NewBundle(env.ConfigPath)
 	if err != nil {
@@ -62,7 +95,21 @@ func main() {
 	defer bundleService.Close(ctx)
 
 	// 3. Connect to orchestrator with resilience
-	transportCreds, err := buildTransportCredentials(env)
+	//
+	// When a cert/key pair is configured, certmanager.Manager owns it
+	// instead of a one-shot tls.LoadX509KeyPair: it watches the files and
+	// republishes the parsed pair on rotation, so renewing the gateway's
+	// mTLS identity no longer requires a restart.
+	var certMgr *certmanager.Manager
+	if env.IsProduction() && env.GRPCTlsCert != "" && env.GRPCTlsKey != "" {
+		certMgr, err = certmanager.New(env.GRPCTlsCa, env.GRPCTlsCert, env.GRPCTlsKey)
+		if err != nil {
+			log.Fatalf("Failed to start cert manager: %v", err)
+		}
+		defer certMgr.Close()
+	}
+
+	transportCreds, err := buildTransportCredentials(env, certMgr)
 	if err != nil {
 		log.Fatalf("Failed to build transport credentials: %v", err)
 	}
@@ -83,6 +130,23 @@ func main() {
 		// User-agent — ArgoCD apiclient.go line 541:
 		//   dialOpts = append(dialOpts, grpc.WithUserAgent(c.UserAgent))
 		grpc.WithUserAgent("gateway-service"),
+
+		// otelgrpc's stats handler propagates the traceparent extracted by
+		// otelhttp (see internal.Router.buildMiddlewareChain) onto this
+		// call's outgoing metadata and records a client span per RPC, so a
+		// webhook's trace continues into the orchestrator instead of
+		// stopping at the gateway's edge.
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+
+		// Interceptor chain — mirrors grpc-ecosystem's recovery/logging
+		// middleware chains. MetadataPropagator runs first so the
+		// request/correlation IDs are already on outgoing metadata
+		// before the metrics interceptor times the call.
+		grpc.WithChainUnaryInterceptor(
+			middlewares.UnaryClientMetadataPropagator(),
+			metrics.UnaryClientInterceptor(),
+		),
+		grpc.WithChainStreamInterceptor(middlewares.StreamClientMetadataPropagator()),
 	)
 	if err != nil {
 		log.Fatalf("Failed to connect to orchestrator: %v", err)
@@ -91,10 +155,55 @@ func main() {
 
 	grpcClient := pb.NewOrchestratorClient(conn)
 
+	// 3b. Optional webhook schema overrides/additions — lets an operator
+	// register a new platform's structural validation (or tighten an
+	// existing one) by dropping files under GATEWAY_SCHEMAS_DIR, without
+	// recompiling the gateway. The embedded defaults (GitHub/GitLab/
+	// Bitbucket) are loaded unconditionally at package init.
+	if dir := os.Getenv("GATEWAY_SCHEMAS_DIR"); dir != "" {
+		if err := handlers.DefaultSchemaRegistry.LoadDir(dir); err != nil {
+			log.Fatalf("Failed to load webhook schema overrides from %s: %v", dir, err)
+		}
+		slog.Info("webhook schemas: loaded overrides", "dir", dir)
+	}
+
 	// 4. Wire router
 	r := internal.NewRouter(bundleService, grpcClient, conn)
 
-	// 5. Start admin server on a separate port.
+	// 4b. Deep readiness probes — one per configured SecretManager and
+	// Platform driver, on top of the orchestrator gRPC probe
+	// NewHealthHandler already registers. Non-critical: a single bad
+	// secret manager or SCM credential shouldn't 503 the whole gateway,
+	// just surface as DOWN in the readiness payload for dashboards/alerts.
+	for _, name := range bundleService.ListConfiguredSecretManagers() {
+		r.HealthHandler().RegisterProbe(handlers.NewSecretManagerProbe(name, bundleService), handlers.ProbeOpts{
+			Timeout:  3 * time.Second,
+			CacheTTL: 30 * time.Second,
+			Critical: false,
+		})
+	}
+	for _, name := range bundleService.ListConfiguredPlatforms() {
+		r.HealthHandler().RegisterProbe(handlers.NewPlatformProbe(name, bundleService), handlers.ProbeOpts{
+			Timeout:  3 * time.Second,
+			CacheTTL: 30 * time.Second,
+			Critical: false,
+		})
+	}
+
+	// 5. Resolve inbound TLS config from environment.
+	//
+	// Four modes, checked in this order (all but ACME mirror ArgoCD's
+	// CreateServerTLSConfig):
+	//   a) GATEWAY_TLS_CERT + GATEWAY_TLS_KEY set → load from files
+	//   b) Neither set + GATEWAY_ACME_ENABLED=true → ACME (Let's Encrypt)
+	//   c) Neither set + test mode → self-signed dev CA (ArgoCD's fallback,
+	//      persisted to GATEWAY_TLS_CACHE_DIR so restarts reuse it)
+	//   d) Neither set + production → plaintext (behind Ingress/LB)
+	// Resolved before the admin server so a generated dev CA's PEM can be
+	// wired into AdminConfig for /admin/ca.pem.
+	tlsCfg, devCACertPEM := resolveInboundTLS(env)
+
+	// 6. Start admin server on a separate port.
 	//
 	// Mirrors ArgoCD's server.go which starts metricsServ on a dedicated
 	// port in a goroutine:
@@ -105,10 +214,19 @@ func main() {
 	// health, pprof, and version on "admin.http.host-port", separate
 	// from the main query/collector ports.
 	adminAddr := envOr("ADMIN_ADDR", ":9090")
-	adminSrv := internal.NewAdminServer(internal.AdminConfig{
-		Addr:        adminAddr,
-		EnablePprof: !env.IsProduction(),
+	adminSrv, err := internal.NewAdminServer(internal.AdminConfig{
+		Addr:                 adminAddr,
+		EnablePprof:          !env.IsProduction(),
+		EnableGateway:        envOr("ADMIN_ENABLE_GATEWAY", "") == "true",
+		EnableMetrics:        envOr("ADMIN_ENABLE_METRICS", "true") == "true",
+		OrchestratorEndpoint: env.GetOrchestratorAddr(),
+		DialOptions:          []grpc.DialOption{grpc.WithTransportCredentials(transportCreds)},
+		DevCACertPEM:         devCACertPEM,
+		Breaker:              r.CircuitBreaker(),
 	}, r.HealthHandler())
+	if err != nil {
+		log.Fatalf("Failed to start admin server: %v", err)
+	}
 
 	go func() {
 		if err := adminSrv.Serve(); err != nil {
@@ -116,7 +234,31 @@ func main() {
 		}
 	}()
 
-	// 6. Graceful shutdown — ArgoCD's signal → available.Store(false) → Shutdown
+	// 6b. Config hot-reload — SIGHUP or a change to .env/CONFIG_PATH
+	// re-runs LoadEnv and fans the result out to subscribers, so rotating
+	// a log level (or, once they subscribe, certs/Vault tokens) never
+	// requires a pod restart. A bad candidate config is logged and
+	// discarded; the process keeps serving the last-good Env.
+	reloader, err := config.NewReloader(env, config.WithDegradeHook(r.HealthHandler().SetDegraded))
+	if err != nil {
+		log.Fatalf("Failed to start config reloader: %v", err)
+	}
+	reloader.Subscribe(r.HealthHandler())
+	reloader.Subscribe(logLevelReloadable{})
+	go reloader.Run(ctx)
+
+	// 6c. Surface TLS identity health. certMgr is constructed in step 3,
+	// before r.HealthHandler() exists, so the hook is wired here instead
+	// of via certmanager.New's options: push the already-known state once,
+	// then subscribe to future rotations/failures.
+	if certMgr != nil {
+		r.HealthHandler().SetCertsUnavailable(!certMgr.Ready())
+		certMgr.SetReadyChangeHook(func(ready bool) {
+			r.HealthHandler().SetCertsUnavailable(!ready)
+		})
+	}
+
+	// 7. Graceful shutdown — ArgoCD's signal → available.Store(false) → Shutdown
 	stopCh := make(chan os.Signal, 1)
 	signal.Notify(stopCh, os.Interrupt, syscall.SIGTERM)
 
@@ -136,53 +278,103 @@ func main() {
 		cancel()
 	}()
 
-	// 7. Resolve inbound TLS config from environment.
-	//
-	// Three modes (mirroring ArgoCD's CreateServerTLSConfig):
-	//   a) GATEWAY_TLS_CERT + GATEWAY_TLS_KEY set → load from files
-	//   b) Neither set + test mode → self-signed cert (ArgoCD's fallback)
-	//   c) Neither set + production → plaintext (behind Ingress/LB)
-	tlsCfg := resolveInboundTLS(env)
+	// 8. Resolve inbound PROXY protocol wrapping — nil unless the operator
+	// opted in, since it changes how every RemoteAddr in the stack behaves.
+	var proxyCfg *internal.ProxyProtocolConfig
+	if env.EnableProxyProtocol {
+		slog.Info("inbound PROXY protocol: enabled", "trusted_cidrs", env.ProxyProtocolTrustedCIDRs)
+		proxyCfg = &internal.ProxyProtocolConfig{TrustedCIDRs: env.ProxyProtocolTrustedCIDRs}
+	}
+
+	// grpcServer is nil until the gateway registers its own gRPC service —
+	// Run multiplexes it onto the same port as r via cmux once one exists.
+	internal.Run(ctx, env.GetGatewayAddr(), r, nil, tlsCfg, proxyCfg)
 
-	internal.Run(ctx, env.GetGatewayAddr(), r, tlsCfg)
+	// Drain any webhooks already accepted (202) but not yet delivered
+	// before the deferred conn.Close() above severs the orchestrator
+	// connection out from under them.
+	r.Close()
 }
 
-// resolveInboundTLS builds the inbound TLS config based on environment.
-func resolveInboundTLS(env *config.Env) *internal.TLSConfig {
+// resolveInboundTLS builds the inbound TLS config based on environment,
+// returning the self-signed dev CA's PEM alongside it (nil unless a dev CA
+// was generated) so the caller can wire it into AdminConfig.DevCACertPEM.
+func resolveInboundTLS(env *config.Env) (*internal.TLSConfig, []byte) {
 	cert := os.Getenv("GATEWAY_TLS_CERT")
 	key := os.Getenv("GATEWAY_TLS_KEY")
 
 	if cert != "" && key != "" {
-		slog.Info("inbound TLS: loading certificate from files",
-			"cert", cert, "key", key)
-		return &internal.TLSConfig{CertFile: cert, KeyFile: key}
+		clientCA := os.Getenv("GATEWAY_TLS_CLIENT_CA")
+		requireClientCert := envOr("GATEWAY_TLS_REQUIRE_CLIENT_CERT", "") == "true"
+		slog.Info("inbound TLS: loading certificate from files (hot-reloadable)",
+			"cert", cert, "key", key, "client_ca", clientCA, "require_client_cert", requireClientCert)
+		return &internal.TLSConfig{
+			CertFile:          cert,
+			KeyFile:           key,
+			ClientCAFile:      clientCA,
+			RequireClientCert: requireClientCert,
+		}, nil
 	}
 
-	// In non-production, generate a self-signed cert so local dev always
-	// uses HTTPS. Mirrors ArgoCD's CreateServerTLSConfig which logs:
+	// ACME (Let's Encrypt) — a production-grade option beyond "cert files
+	// or self-signed dev cert", checked before the dev-CA fallback below
+	// so it applies regardless of env.IsProduction().
+	if envOr("GATEWAY_ACME_ENABLED", "") == "true" {
+		challenge := internal.ACMEChallengeType(envOr("GATEWAY_ACME_CHALLENGE", string(internal.ACMEChallengeTLSALPN01)))
+		return &internal.TLSConfig{
+			ACMEEnabled:       true,
+			ACMEEmail:         os.Getenv("GATEWAY_ACME_EMAIL"),
+			ACMEHosts:         splitCSV(os.Getenv("GATEWAY_ACME_HOSTS")),
+			ACMECacheDir:      envOr("GATEWAY_ACME_CACHE_DIR", ".cache/gateway-acme"),
+			ACMEChallengeType: challenge,
+		}, nil
+	}
+
+	// In non-production, generate (or reuse a cached) self-signed dev CA
+	// so local dev always uses HTTPS. Mirrors ArgoCD's
+	// CreateServerTLSConfig which logs:
 	//   "Generating self-signed TLS certificate for this session"
+	// — GATEWAY_TLS_CACHE_DIR additionally persists ours across restarts,
+	// and GATEWAY_ADVERTISE_HOSTS adds extra SANs (e.g. a docker-compose
+	// service name) beyond localhost/127.0.0.1/::1.
 	if !env.IsProduction() {
-		slog.Info("inbound TLS: self-signed cert for local dev (non-production)")
-		return &internal.TLSConfig{SelfSignedIfMissing: true}
+		cacheDir := envOr("GATEWAY_TLS_CACHE_DIR", ".cache/gateway-tls")
+		hosts := splitCSV(os.Getenv("GATEWAY_ADVERTISE_HOSTS"))
+
+		dca, err := internal.LoadOrGenerateDevCA(cacheDir, hosts)
+		if err != nil {
+			log.Fatalf("Failed to generate self-signed dev TLS certificate: %v", err)
+		}
+
+		slog.Warn("inbound TLS: using a generated self-signed certificate — not for production",
+			"cache_dir", cacheDir, "advertise_hosts", hosts, "ca_download", "GET /admin/ca.pem")
+		return &internal.TLSConfig{SelfSignedCert: &dca.Leaf}, dca.CertPEM
 	}
 
 	// Production without explicit certs: plaintext behind Ingress/LB.
 	slog.Info("inbound TLS: disabled (expects TLS termination upstream)")
-	return nil
+	return nil, nil
 }
 
 // buildTransportCredentials returns TLS credentials for the outbound gRPC
-// connection to the orchestrator.
+// connection to the orchestrator. When certMgr is non-nil (a cert/key pair
+// was configured), it supplies a tls.Config whose client certificate is
+// hot-swappable on rotation instead of the one-shot keypair load below.
 //
 // Uses ArgoCD's tls util BestEffortSystemCertPool pattern: when no custom
 // CA is specified, the system cert pool is used (with a fallback to an
 // empty pool if system certs can't be loaded).
-func buildTransportCredentials(env *config.Env) (credentials.TransportCredentials, error) {
+func buildTransportCredentials(env *config.Env, certMgr *certmanager.Manager) (credentials.TransportCredentials, error) {
 	if !env.IsProduction() {
 		slog.Info("gRPC transport: insecure (test mode)")
 		return insecure.NewCredentials(), nil
 	}
 
+	if certMgr != nil {
+		slog.Info("gRPC transport: TLS (production mode, hot-reloadable cert)")
+		return credentials.NewTLS(certMgr.GetClientTLSConfig()), nil
+	}
+
 	tlsCfg := &tls.Config{}
 
 	if env.GRPCTlsCa != "" {
@@ -227,9 +419,38 @@ func buildTransportCredentials(env *config.Env) (credentials.TransportCredential
 	return credentials.NewTLS(tlsCfg), nil
 }
 
+// logLevelReloadable applies a reloaded Env's LOG_LEVEL to the running
+// slog handler. It's stateless — logger.SetLevelFromString owns the
+// actual slog.LevelVar — so a zero value is a valid config.Reloadable.
+type logLevelReloadable struct{}
+
+func (logLevelReloadable) Reload(env *config.Env) error {
+	logger.SetLevelFromString(env.LogLevel)
+	return nil
+}
+
 func envOr(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
 	}
 	return fallback
 }
+
+// splitCSV parses a comma-separated env var into a trimmed, non-empty
+// slice of values. Returns nil for an empty input. Duplicated from
+// config.splitCSV since GATEWAY_ADVERTISE_HOSTS is read directly here
+// rather than through config.Env, the same way GATEWAY_TLS_CERT already is.
+func splitCSV(v string) []string {
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
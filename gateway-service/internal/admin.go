@@ -2,13 +2,21 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/pprof"
 	"time"
 
+	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
 	"gateway-service/internal/handlers"
+	"gateway-service/internal/metrics"
+	"gateway-service/internal/middlewares"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 )
 
 // AdminServer runs internal endpoints (health, pprof, metrics) on a
@@ -48,6 +56,42 @@ type AdminConfig struct {
 	// behind a config file check; Jaeger always registers them on the
 	// admin server since it's internal-only.
 	EnablePprof bool
+
+	// EnableGateway mounts a grpc-gateway runtime.ServeMux at "/api/"
+	// that transcodes REST/JSON calls into pb.OrchestratorClient gRPC
+	// methods, so operators can curl the orchestrator API for debugging
+	// or wire it into third-party dashboards without a gRPC client.
+	EnableGateway bool
+
+	// OrchestratorEndpoint is the dial address the gateway mux uses to
+	// reach the orchestrator gRPC service (e.g. env.GetOrchestratorAddr()).
+	// Required when EnableGateway is true.
+	OrchestratorEndpoint string
+
+	// DialOptions are passed through to the gateway's own grpc.NewClient
+	// dial (TLS credentials, keepalive, interceptors) so the transcoding
+	// mux talks to the orchestrator under the same security posture as
+	// the main gRPC client built in cmd/main.go.
+	DialOptions []grpc.DialOption
+
+	// EnableMetrics mounts promhttp.HandlerFor(metrics.Registry, ...) at
+	// /metrics. This is the endpoint the doc comment above has always
+	// pointed Prometheus at; it's now backed by a real handler.
+	EnableMetrics bool
+
+	// DevCACertPEM is the self-signed dev CA certificate (see
+	// internal.LoadOrGenerateDevCA), PEM-encoded. Non-nil only when
+	// resolveInboundTLS generated a cert because no GATEWAY_TLS_CERT/KEY
+	// were configured; in that case it's served at /admin/ca.pem so a
+	// developer can curl it straight into their OS/browser trust store
+	// instead of clicking through a warning every session.
+	DevCACertPEM []byte
+
+	// Breaker is the router's shared circuit breaker. Non-nil wires its
+	// per-key state and trip counts at GET /debug/breakers so operators
+	// can see which (platform, datasource) shards are open without
+	// reasoning about it from webhook 503s and logs alone.
+	Breaker *middlewares.CircuitBreaker
 }
 
 // NewAdminServer creates an admin server with health and optional debug
@@ -58,7 +102,7 @@ type AdminConfig struct {
 // Pattern: Jaeger's NewAdminServer(hostPort) which returns an AdminServer
 // with its own mux, and ArgoCD's NewMetricsServer(host, port) which
 // creates a dedicated http.Server.
-func NewAdminServer(cfg AdminConfig, healthHandler *handlers.HealthHandler) *AdminServer {
+func NewAdminServer(cfg AdminConfig, healthHandler *handlers.HealthHandler) (*AdminServer, error) {
 	mux := http.NewServeMux()
 
 	// Health check on root — Jaeger's AdminServer mounts health on "/".
@@ -68,6 +112,30 @@ func NewAdminServer(cfg AdminConfig, healthHandler *handlers.HealthHandler) *Adm
 	mux.HandleFunc("GET /health/liveness", healthHandler.Liveness)
 	mux.HandleFunc("GET /health/readiness", healthHandler.Readiness)
 
+	// Prometheus scrape endpoint.
+	if cfg.EnableMetrics {
+		mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	}
+
+	// Dev CA download — only mounted while running on a generated cert,
+	// so it disappears the moment a real GATEWAY_TLS_CERT is configured.
+	if len(cfg.DevCACertPEM) > 0 {
+		mux.HandleFunc("GET /admin/ca.pem", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-pem-file")
+			w.Write(cfg.DevCACertPEM)
+		})
+	}
+
+	// Circuit breaker introspection — one JSON object per shard key,
+	// mirroring the per-generation counts/half-open state
+	// middlewares.CircuitBreaker.Snapshot tracks internally.
+	if cfg.Breaker != nil {
+		mux.HandleFunc("GET /debug/breakers", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(cfg.Breaker.Snapshot())
+		})
+	}
+
 	// pprof — Jaeger's registerPprofHandlers() and ArgoCD's
 	// profile.RegisterProfiler(mux) both register the same set of
 	// endpoints on the admin/metrics mux.
@@ -83,6 +151,34 @@ func NewAdminServer(cfg AdminConfig, healthHandler *handlers.HealthHandler) *Adm
 		mux.Handle("/debug/pprof/block", pprof.Handler("block"))
 	}
 
+	// grpc-gateway JSON transcoding — mounts the generated
+	// pb.RegisterOrchestratorHandlerFromEndpoint (emitted under
+	// internal/gen/proto as *.pb.gw.go) at "/api/", so REST/JSON callers
+	// (curl, Grafana, ArgoCD-style dashboards) can hit orchestrator RPCs
+	// without a gRPC client. This mirrors how ArgoCD's server embeds a
+	// grpc-gateway mux alongside its native gRPC API.
+	if cfg.EnableGateway {
+		if cfg.OrchestratorEndpoint == "" {
+			return nil, fmt.Errorf("admin server: EnableGateway requires OrchestratorEndpoint")
+		}
+
+		gwMux := runtime.NewServeMux(
+			// Allow-list the headers the webhook handler already treats
+			// as significant so they reach the orchestrator unchanged,
+			// same intent as the middleware chain's X-Request-ID /
+			// X-Correlation-ID propagation.
+			runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		)
+
+		if err := pb.RegisterOrchestratorHandlerFromEndpoint(
+			context.Background(), gwMux, cfg.OrchestratorEndpoint, cfg.DialOptions,
+		); err != nil {
+			return nil, fmt.Errorf("admin server: failed to register grpc-gateway handler: %w", err)
+		}
+
+		mux.Handle("/api/", http.StripPrefix("/api", gwMux))
+	}
+
 	return &AdminServer{
 		server: &http.Server{
 			Addr:              cfg.Addr,
@@ -93,6 +189,19 @@ func NewAdminServer(cfg AdminConfig, healthHandler *handlers.HealthHandler) *Adm
 			IdleTimeout:       60 * time.Second,
 		},
 		healthHandler: healthHandler,
+	}, nil
+}
+
+// gatewayHeaderMatcher allow-lists the inbound headers forwarded to the
+// orchestrator gRPC metadata by the transcoding mux. Everything else is
+// dropped, mirroring grpc-gateway's default deny-by-default posture for
+// non-"Grpc-Metadata-" prefixed headers.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	switch http.CanonicalHeaderKey(key) {
+	case "X-Request-Id", "X-Correlation-Id", "Authorization":
+		return key, true
+	default:
+		return "", false
 	}
 }
 
@@ -0,0 +1,327 @@
+// Package certmanager hot-swaps the gateway's gRPC TLS identity — the
+// client cert used to dial the orchestrator and (eventually) the server
+// cert used by an inbound gRPC listener — and verifies peer SPIFFE IDs
+// on mTLS connections.
+//
+// It exists because buildTransportCredentials in cmd/main.go loads
+// Env.GRPCTlsCert/Key exactly once at startup: rotating a cert today
+// means restarting the process. Manager instead watches the cert/key/CA
+// files and atomically republishes the parsed pair, so in-flight streams
+// are unaffected — new handshakes simply pick up the new pair via the
+// tls.Config's GetCertificate/GetClientCertificate callbacks.
+package certmanager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// pair bundles a parsed keypair with the CA pool active at load time, so
+// a reader always sees both halves from the same rotation.
+type pair struct {
+	cert *tls.Certificate
+	cas  *x509.CertPool
+}
+
+// Manager owns the current (cert, key, CA) triple for mTLS between the
+// gateway and the orchestrator, refreshing it whenever any of the three
+// files change on disk.
+type Manager struct {
+	caFile, certFile, keyFile string
+
+	current atomic.Pointer[pair]
+	ready   atomic.Bool
+
+	// allowlist maps a tenant/platform name to the SPIFFE ID URI SAN
+	// patterns it's allowed to present (path.Match syntax, e.g.
+	// "spiffe://vartrack.internal/ns/*/sa/orchestrator").
+	allowlist map[string][]string
+
+	// onReadyChange is invoked whenever a reload flips whether Manager
+	// has a valid pair loaded. Wired to AdminServer.healthHandler in
+	// cmd/main.go so readiness reflects cert health.
+	onReadyChange func(ready bool)
+
+	watcher *fsnotify.Watcher
+	stopCh  chan struct{}
+}
+
+// Option configures optional Manager behaviour.
+type Option func(*Manager)
+
+// WithAllowlist configures the per-tenant SPIFFE ID patterns enforced by
+// VerifyPeerCertificate in GetServerTLSConfig.
+func WithAllowlist(allowlist map[string][]string) Option {
+	return func(m *Manager) { m.allowlist = allowlist }
+}
+
+// WithReadyChangeHook registers a callback fired every time Manager's
+// readiness (has a valid cert/key pair loaded) changes.
+func WithReadyChangeHook(fn func(ready bool)) Option {
+	return func(m *Manager) { m.onReadyChange = fn }
+}
+
+// New creates a Manager, loads the initial pair, and starts watching
+// caFile/certFile/keyFile for rotation. caFile may be empty if the
+// gateway only needs a client cert and trusts the system root pool.
+func New(caFile, certFile, keyFile string, opts ...Option) (*Manager, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("certmanager: failed to create fsnotify watcher: %w", err)
+	}
+
+	m := &Manager{
+		caFile:   caFile,
+		certFile: certFile,
+		keyFile:  keyFile,
+		watcher:  watcher,
+		stopCh:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if err := m.reload(); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("certmanager: initial load failed: %w", err)
+	}
+
+	for _, f := range m.watchedFiles() {
+		// Watch the containing directory rather than the file itself:
+		// most rotation tools (cert-manager, kubelet projected secrets)
+		// replace files via rename, which doesn't re-trigger a watch on
+		// the old inode.
+		dir := path.Dir(f)
+		if err := watcher.Add(dir); err != nil {
+			slog.Warn("certmanager: failed to watch directory", "dir", dir, "error", err)
+		}
+	}
+
+	go m.run()
+	return m, nil
+}
+
+func (m *Manager) watchedFiles() []string {
+	files := []string{m.certFile, m.keyFile}
+	if m.caFile != "" {
+		files = append(files, m.caFile)
+	}
+	return files
+}
+
+func (m *Manager) run() {
+	defer m.watcher.Close()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if !m.watches(event.Name) {
+				continue
+			}
+			slog.Info("certmanager: detected change, reloading cert pair", "path", event.Name)
+			if err := m.reload(); err != nil {
+				slog.Error("certmanager: reload failed, keeping previous cert pair", "error", err)
+			}
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("certmanager: watcher error", "error", err)
+		}
+	}
+}
+
+func (m *Manager) watches(name string) bool {
+	for _, f := range m.watchedFiles() {
+		if path.Base(f) == path.Base(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload loads a fresh cert/key pair and CA pool. It never displaces the
+// previously-loaded pair on failure, so a bad rotation leaves the gateway
+// serving its last-known-good identity instead of going dark.
+func (m *Manager) reload() error {
+	cert, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		m.setReady(false)
+		return fmt.Errorf("failed to load keypair (cert=%s, key=%s): %w", m.certFile, m.keyFile, err)
+	}
+
+	cas, err := x509.SystemCertPool()
+	if err != nil || cas == nil {
+		cas = x509.NewCertPool()
+	}
+	if m.caFile != "" {
+		caPEM, err := os.ReadFile(m.caFile)
+		if err != nil {
+			m.setReady(false)
+			return fmt.Errorf("failed to read CA file %s: %w", m.caFile, err)
+		}
+		if !cas.AppendCertsFromPEM(caPEM) {
+			m.setReady(false)
+			return fmt.Errorf("failed to parse CA certificate from %s", m.caFile)
+		}
+	}
+
+	m.current.Store(&pair{cert: &cert, cas: cas})
+	m.setReady(true)
+	return nil
+}
+
+func (m *Manager) setReady(ready bool) {
+	if m.ready.Swap(ready) == ready {
+		return
+	}
+	if m.onReadyChange != nil {
+		m.onReadyChange(ready)
+	}
+}
+
+// Ready reports whether a valid cert/key pair is currently loaded.
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// SetReadyChangeHook wires (or replaces) the callback invoked on future
+// readiness changes. Useful when the dependent component — e.g.
+// HealthHandler — isn't constructed yet at New() time; the caller passes
+// the current state through once manually and then wires future changes
+// with this.
+func (m *Manager) SetReadyChangeHook(fn func(ready bool)) {
+	m.onReadyChange = fn
+}
+
+// GetClientTLSConfig returns a *tls.Config for the outbound orchestrator
+// dialer. GetClientCertificate always reads the latest rotated pair, so
+// cert rotation takes effect on the next handshake (e.g. after a
+// keepalive-triggered reconnect) without tearing down the gRPC client.
+func (m *Manager) GetClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    m.current.Load().cas,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			p := m.current.Load()
+			if p == nil {
+				return nil, fmt.Errorf("certmanager: no certificate pair loaded")
+			}
+			return p.cert, nil
+		},
+	}
+}
+
+// GetServerTLSConfig returns a *tls.Config for an inbound mTLS gRPC
+// listener (the future admin gRPC server): it requires and verifies
+// client certs, then checks the peer's SPIFFE ID (the URI SAN) against
+// the per-tenant allow-list.
+func (m *Manager) GetServerTLSConfig(tenant string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  m.current.Load().cas,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			p := m.current.Load()
+			if p == nil {
+				return nil, fmt.Errorf("certmanager: no certificate pair loaded")
+			}
+			return p.cert, nil
+		},
+		VerifyPeerCertificate: m.verifySPIFFEID(tenant),
+	}
+}
+
+// verifySPIFFEID returns a VerifyPeerCertificate callback that checks the
+// leaf cert's URI SAN against tenant's allow-listed SPIFFE ID patterns.
+// crypto/tls has already verified the chain by this point (ClientAuth is
+// RequireAndVerifyClientCert); this adds identity authorization on top of
+// chain trust, the same two-step go-spiffe's tlsconfig.MTLSServerConfig
+// performs (verify chain, then check the workload's ID against a policy).
+func (m *Manager) verifySPIFFEID(tenant string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		patterns := m.allowlist[tenant]
+		if len(patterns) == 0 {
+			return fmt.Errorf("certmanager: no SPIFFE ID allow-list configured for tenant %q", tenant)
+		}
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("certmanager: no peer certificate presented")
+		}
+
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("certmanager: failed to parse peer certificate: %w", err)
+		}
+
+		for _, uri := range leaf.URIs {
+			id := uri.String()
+			for _, pattern := range patterns {
+				if matched, _ := path.Match(pattern, id); matched {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("certmanager: peer SPIFFE ID not in tenant %q's allow-list", tenant)
+	}
+}
+
+// GetHTTPServerTLSConfig returns a *tls.Config for the inbound HTTPS
+// webhook listener (internal.Run / buildServerTLSConfig). Unlike
+// GetServerTLSConfig (the admin gRPC listener, which also gates on a
+// per-tenant SPIFFE ID), this is a general-purpose mTLS option for
+// upstream sidecars: requireClientCert selects between optional
+// (VerifyClientCertIfGiven) and mandatory (RequireAndVerifyClientCert)
+// client certificate verification. Both GetCertificate and
+// GetConfigForClient read m.current on every handshake, so a cert/key/CA
+// rotation on disk — picked up by the fsnotify watcher started in New —
+// takes effect on the next handshake without restarting the listener,
+// mirroring etcd's client transport's Root CA rotation.
+func (m *Manager) GetHTTPServerTLSConfig(requireClientCert bool) *tls.Config {
+	clientAuth := tls.VerifyClientCertIfGiven
+	if requireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			p := m.current.Load()
+			if p == nil {
+				return nil, fmt.Errorf("certmanager: no certificate pair loaded")
+			}
+			return p.cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			p := m.current.Load()
+			if p == nil {
+				return nil, fmt.Errorf("certmanager: no certificate pair loaded")
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				ClientAuth:   clientAuth,
+				ClientCAs:    p.cas,
+				Certificates: []tls.Certificate{*p.cert},
+			}, nil
+		},
+	}
+}
+
+// Close stops the background watcher.
+func (m *Manager) Close() error {
+	close(m.stopCh)
+	return nil
+}
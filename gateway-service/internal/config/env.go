@@ -25,6 +25,18 @@ type Env struct {
 	GRPCTlsCa        string // GRPC_TLS_CA — path to CA cert for outbound gRPC
 	GRPCTlsCert      string // GRPC_TLS_CERT — path to client cert (mTLS)
 	GRPCTlsKey       string // GRPC_TLS_KEY — path to client key (mTLS)
+
+	// EnableProxyProtocol wraps the inbound listener with a PROXY
+	// protocol (v1/v2) decoder, for deployments sitting behind an L4
+	// load balancer (AWS NLB, HAProxy TCP mode) that preserves the real
+	// client IP this way instead of X-Forwarded-For.
+	EnableProxyProtocol bool // ENABLE_PROXY_PROTOCOL
+
+	// ProxyProtocolTrustedCIDRs lists the upstream CIDRs allowed to send
+	// a PROXY header; a connection from any other source has its header
+	// rejected; the socket's real address is used as the client IP instead
+	// of letting an untrusted peer spoof it.
+	ProxyProtocolTrustedCIDRs []string // PROXY_PROTOCOL_TRUSTED_CIDRS — comma-separated
 }
 
 func (e *Env) GetOrchestratorAddr() string { return e.OrchestratorAddr }
@@ -54,6 +66,9 @@ func LoadEnv() (*Env, error) {
 		GRPCTlsCa:        os.Getenv("GRPC_TLS_CA"),
 		GRPCTlsCert:      os.Getenv("GRPC_TLS_CERT"),
 		GRPCTlsKey:       os.Getenv("GRPC_TLS_KEY"),
+
+		EnableProxyProtocol:       envOr("ENABLE_PROXY_PROTOCOL", "false") == "true",
+		ProxyProtocolTrustedCIDRs: splitCSV(os.Getenv("PROXY_PROTOCOL_TRUSTED_CIDRS")),
 	}
 
 	env.AppEnv = strings.ToLower(strings.TrimSpace(env.AppEnv))
@@ -186,6 +201,20 @@ func (e *Env) validate() error {
 		}
 	}
 
+	// PROXY protocol — every trusted CIDR must parse, and enabling the
+	// feature without any trusted source would accept a PROXY header from
+	// literally anyone, defeating the point of the allow-list.
+	if e.EnableProxyProtocol {
+		if len(e.ProxyProtocolTrustedCIDRs) == 0 {
+			return fmt.Errorf("ENABLE_PROXY_PROTOCOL=true requires at least one entry in PROXY_PROTOCOL_TRUSTED_CIDRS")
+		}
+		for _, cidr := range e.ProxyProtocolTrustedCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("PROXY_PROTOCOL_TRUSTED_CIDRS: %q is not a valid CIDR: %w", cidr, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -220,3 +249,20 @@ func envOr(key, fallback string) string {
 	}
 	return fallback
 }
+
+// splitCSV parses a comma-separated env var into a trimmed, non-empty
+// slice of values. Returns nil for an empty input.
+func splitCSV(v string) []string {
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -10,7 +10,8 @@ import (
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/load"
 
-	_ "gateway-service/internal/models/platforms"
+	_ "gateway-service/internal/models/platform"
+	_ "gateway-service/internal/models/secret_managers"
 )
 
 func NewBundle(cuePath string) (*models.Bundle, error) {
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"gateway-service/internal/metrics"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reloadable is implemented by anything that needs to react to a
+// successful config hot-reload — the HealthHandler clears its degraded
+// state, platform/secret-manager factories can re-derive credentials that
+// came from env-sourced secrets, and so on.
+type Reloadable interface {
+	Reload(*Env) error
+}
+
+// Reloader watches the .env file and CONFIG_PATH for changes (via
+// fsnotify) and listens for SIGHUP, re-running LoadEnv on every trigger
+// and fanning the result out to registered subscribers.
+//
+// Mirrors Consul's configReloaders pattern (agent/config/runtime.go):
+// a reload always validates a full candidate config first, and a bad
+// candidate never displaces the last-good one — the process keeps
+// serving its previous config rather than crashing or going dark.
+type Reloader struct {
+	mu          sync.RWMutex
+	current     *Env
+	subscribers []Reloadable
+	onDegrade   func(err error)
+
+	watcher *fsnotify.Watcher
+	sigCh   chan os.Signal
+}
+
+// ReloaderOption configures optional Reloader behaviour.
+type ReloaderOption func(*Reloader)
+
+// WithDegradeHook registers a callback invoked whenever a reload attempt
+// fails (bad candidate config, or a subscriber rejecting it) and cleared
+// whenever one later succeeds. Wired to HealthHandler.SetDegraded in
+// cmd/main.go so readiness probes can surface it.
+func WithDegradeHook(fn func(err error)) ReloaderOption {
+	return func(r *Reloader) { r.onDegrade = fn }
+}
+
+// NewReloader creates a Reloader seeded with the already-loaded initial
+// Env and starts watching its .env file and ConfigPath.
+func NewReloader(initial *Env, opts ...ReloaderOption) (*Reloader, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("config reloader: failed to create fsnotify watcher: %w", err)
+	}
+
+	r := &Reloader{
+		current: initial,
+		watcher: watcher,
+		sigCh:   make(chan os.Signal, 1),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	for _, path := range watchPaths(initial) {
+		if err := watcher.Add(path); err != nil {
+			slog.Warn("config reloader: failed to watch path, changes to it will require SIGHUP",
+				"path", path, "error", err)
+		}
+	}
+
+	signal.Notify(r.sigCh, syscall.SIGHUP)
+	return r, nil
+}
+
+// watchPaths returns the files a reload should be triggered by: the CUE
+// bundle and whichever .env file LoadEnv's dotenv loader would have used.
+func watchPaths(env *Env) []string {
+	paths := []string{env.ConfigPath}
+
+	// Same candidates, in the same order, as loadDotEnv: the first one
+	// that exists is the file it loaded.
+	for _, candidate := range []string{os.Getenv("ENV_FILE"), ".env", "../.env"} {
+		if candidate == "" {
+			continue
+		}
+		if _, err := os.Stat(candidate); err == nil {
+			paths = append(paths, candidate)
+			break
+		}
+	}
+	return paths
+}
+
+// Subscribe registers a Reloadable to be notified after a reload that
+// both validates and is accepted.
+func (r *Reloader) Subscribe(s Reloadable) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, s)
+}
+
+// Current returns the most recently applied Env.
+func (r *Reloader) Current() *Env {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Run blocks, reloading on fsnotify events or SIGHUP, until ctx is
+// cancelled. Intended to be run in its own goroutine alongside the
+// server, the same way the admin server and signal-handling goroutine
+// are started in cmd/main.go.
+func (r *Reloader) Run(ctx context.Context) {
+	defer r.watcher.Close()
+	defer signal.Stop(r.sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case sig, ok := <-r.sigCh:
+			if !ok {
+				return
+			}
+			slog.Info("config reloader: received signal, reloading", "signal", sig.String())
+			r.reload()
+
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			slog.Info("config reloader: detected file change, reloading", "path", event.Name)
+			r.reload()
+
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("config reloader: watcher error", "error", err)
+		}
+	}
+}
+
+// reload loads and validates a candidate Env, swaps it in only if it's
+// valid, and fans it out to subscribers. Subscriber failures are logged
+// and counted but never roll back the swapped-in Env — the candidate was
+// still valid, it's only a downstream component (e.g. a Vault client)
+// that failed to apply it.
+func (r *Reloader) reload() {
+	candidate, err := LoadEnv()
+	if err != nil {
+		slog.Error("config reloader: candidate config invalid, keeping previous config", "error", err)
+		metrics.RecordConfigReload(false)
+		r.degrade(fmt.Errorf("candidate config invalid: %w", err))
+		return
+	}
+
+	r.mu.Lock()
+	r.current = candidate
+	subs := append([]Reloadable(nil), r.subscribers...)
+	r.mu.Unlock()
+
+	var failures []error
+	for _, s := range subs {
+		if err := s.Reload(candidate); err != nil {
+			failures = append(failures, err)
+			slog.Error("config reloader: subscriber failed to apply reload", "error", err)
+		}
+	}
+
+	if len(failures) > 0 {
+		metrics.RecordConfigReload(false)
+		r.degrade(fmt.Errorf("%d subscriber(s) failed to apply reload: %w", len(failures), failures[0]))
+		return
+	}
+
+	metrics.RecordConfigReload(true)
+	r.degrade(nil)
+}
+
+func (r *Reloader) degrade(err error) {
+	if r.onDegrade != nil {
+		r.onDegrade(err)
+	}
+}
@@ -0,0 +1,297 @@
+package internal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// devCAValidity and devLeafValidity bound how long a generated dev CA/cert
+// is trusted before LoadOrGenerateDevCA discards the cached pair and
+// starts over. devLeafValidity mirrors the ~397-day cap the CA/Browser
+// Forum enforces on publicly-trusted certs; there's no real reason a
+// private dev CA needs to follow it, but there's no reason not to either.
+const (
+	devCAValidity   = 5 * 365 * 24 * time.Hour
+	devLeafValidity = 397 * 24 * time.Hour
+)
+
+const (
+	devCACertFile   = "dev-ca-cert.pem"
+	devCAKeyFile    = "dev-ca-key.pem"
+	devLeafCertFile = "dev-leaf-cert.pem"
+	devLeafKeyFile  = "dev-leaf-key.pem"
+)
+
+// DevCA is a self-signed CA and a leaf certificate it issued, used by
+// resolveInboundTLS in non-production when no GATEWAY_TLS_CERT/KEY are
+// configured. Splitting CA and leaf (rather than the single self-signed
+// cert this used to be) means /admin/ca.pem hands back something a
+// developer can actually import into a trust store once and keep — the
+// leaf can be regenerated under it without asking for that trust again.
+type DevCA struct {
+	// CertPEM is the CA certificate, PEM-encoded, served at /admin/ca.pem.
+	CertPEM []byte
+
+	// Leaf is the CA-issued server certificate the HTTP server presents.
+	Leaf tls.Certificate
+
+	caCertDER   []byte
+	caKey       *ecdsa.PrivateKey
+	leafCertDER []byte
+	leafKey     *ecdsa.PrivateKey
+}
+
+// LoadOrGenerateDevCA loads a previously generated CA+leaf pair from
+// cacheDir, or generates and persists a fresh one when the cache is
+// missing, expired, or covers a different set of hosts than requested.
+// cacheDir == "" skips persistence, generating a fresh pair every call.
+func LoadOrGenerateDevCA(cacheDir string, hosts []string) (*DevCA, error) {
+	if cacheDir != "" {
+		if dca, err := loadDevCA(cacheDir, hosts); err == nil {
+			slog.Info("inbound TLS: reusing cached self-signed dev CA", "cache_dir", cacheDir)
+			return dca, nil
+		}
+	}
+
+	dca, err := generateDevCA(hosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate self-signed dev CA: %w", err)
+	}
+
+	if cacheDir != "" {
+		if err := dca.persist(cacheDir); err != nil {
+			// Best-effort: regenerating on every restart is annoying for
+			// a developer's browser trust, but not worth failing startup
+			// over.
+			slog.Warn("failed to persist self-signed dev CA, it will be regenerated on next restart",
+				"cache_dir", cacheDir, "error", err)
+		}
+	}
+	return dca, nil
+}
+
+// generateDevCA creates a fresh P-256 ECDSA CA and a leaf certificate it
+// signs, with SANs for localhost/127.0.0.1/::1 plus hosts.
+func generateDevCA(hosts []string) (*DevCA, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := x509.Certificate{
+		SerialNumber:          newSerial(),
+		Subject:               pkix.Name{Organization: []string{"gateway-service (dev CA)"}, CommonName: "gateway-service dev CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(devCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caCertDER, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(caCertDER)
+	if err != nil {
+		return nil, err
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	dnsNames, ips := devSANs(hosts)
+	leafTemplate := x509.Certificate{
+		SerialNumber: newSerial(),
+		Subject:      pkix.Name{Organization: []string{"gateway-service (self-signed)"}},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(devLeafValidity),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	leafCertDER, err := x509.CreateCertificate(rand.Reader, &leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DevCA{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCertDER}),
+		Leaf: tls.Certificate{
+			Certificate: [][]byte{leafCertDER, caCertDER},
+			PrivateKey:  leafKey,
+		},
+		caCertDER:   caCertDER,
+		caKey:       caKey,
+		leafCertDER: leafCertDER,
+		leafKey:     leafKey,
+	}, nil
+}
+
+func newSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		// crypto/rand failing is not something a caller can meaningfully
+		// recover from either; a zero serial is preferable to a panic.
+		return big.NewInt(0)
+	}
+	return serial
+}
+
+// devSANs builds the leaf's SANs: localhost/127.0.0.1/::1 always, plus
+// hosts, each classified as a DNS name or IP literal.
+func devSANs(hosts []string) ([]string, []net.IP) {
+	dnsNames := []string{"localhost"}
+	ips := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			ips = append(ips, ip)
+			continue
+		}
+		dnsNames = append(dnsNames, h)
+	}
+	return dnsNames, ips
+}
+
+// persist writes the CA and leaf cert/key pairs to cacheDir as PEM files,
+// so a restart can pick them back up via loadDevCA instead of generating
+// (and asking a developer to re-trust) a brand new CA.
+func (d *DevCA) persist(cacheDir string) error {
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		return err
+	}
+
+	caKeyDER, err := x509.MarshalECPrivateKey(d.caKey)
+	if err != nil {
+		return err
+	}
+	leafKeyDER, err := x509.MarshalECPrivateKey(d.leafKey)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name  string
+		block *pem.Block
+		mode  os.FileMode
+	}{
+		{devCACertFile, &pem.Block{Type: "CERTIFICATE", Bytes: d.caCertDER}, 0o644},
+		{devCAKeyFile, &pem.Block{Type: "EC PRIVATE KEY", Bytes: caKeyDER}, 0o600},
+		{devLeafCertFile, &pem.Block{Type: "CERTIFICATE", Bytes: d.leafCertDER}, 0o644},
+		{devLeafKeyFile, &pem.Block{Type: "EC PRIVATE KEY", Bytes: leafKeyDER}, 0o600},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(cacheDir, f.name), pem.EncodeToMemory(f.block), f.mode); err != nil {
+			return fmt.Errorf("write %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// loadDevCA reads a previously persisted CA+leaf pair from cacheDir,
+// rejecting it (so the caller regenerates) if either half has expired or
+// the leaf's SANs no longer match hosts.
+func loadDevCA(cacheDir string, hosts []string) (*DevCA, error) {
+	caCertDER, caKey, err := readCertKeyPair(cacheDir, devCACertFile, devCAKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	leafCertDER, leafKey, err := readCertKeyPair(cacheDir, devLeafCertFile, devLeafKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	caCert, err := x509.ParseCertificate(caCertDER)
+	if err != nil {
+		return nil, fmt.Errorf("parse cached CA cert: %w", err)
+	}
+	if time.Now().After(caCert.NotAfter) {
+		return nil, fmt.Errorf("cached dev CA certificate expired at %s", caCert.NotAfter)
+	}
+
+	leafCert, err := x509.ParseCertificate(leafCertDER)
+	if err != nil {
+		return nil, fmt.Errorf("parse cached leaf cert: %w", err)
+	}
+	if time.Now().After(leafCert.NotAfter) {
+		return nil, fmt.Errorf("cached dev leaf certificate expired at %s", leafCert.NotAfter)
+	}
+	wantDNS, wantIPs := devSANs(hosts)
+	if !sameSANs(leafCert.DNSNames, wantDNS, leafCert.IPAddresses, wantIPs) {
+		return nil, fmt.Errorf("cached dev leaf certificate SANs no longer match GATEWAY_ADVERTISE_HOSTS")
+	}
+
+	return &DevCA{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCertDER}),
+		Leaf: tls.Certificate{
+			Certificate: [][]byte{leafCertDER, caCertDER},
+			PrivateKey:  leafKey,
+		},
+		caCertDER:   caCertDER,
+		caKey:       caKey,
+		leafCertDER: leafCertDER,
+		leafKey:     leafKey,
+	}, nil
+}
+
+func readCertKeyPair(cacheDir, certFile, keyFile string) ([]byte, *ecdsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(filepath.Join(cacheDir, certFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(cacheDir, keyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("%s: not a valid PEM file", certFile)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("%s: not a valid PEM file", keyFile)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", keyFile, err)
+	}
+	return certBlock.Bytes, key, nil
+}
+
+func sameSANs(gotDNS, wantDNS []string, gotIPs, wantIPs []net.IP) bool {
+	if !slices.Equal(sortedCopy(gotDNS), sortedCopy(wantDNS)) {
+		return false
+	}
+	if len(gotIPs) != len(wantIPs) {
+		return false
+	}
+	gotStrs := make([]string, len(gotIPs))
+	for i, ip := range gotIPs {
+		gotStrs[i] = ip.String()
+	}
+	wantStrs := make([]string, len(wantIPs))
+	for i, ip := range wantIPs {
+		wantStrs[i] = ip.String()
+	}
+	return slices.Equal(sortedCopy(gotStrs), sortedCopy(wantStrs))
+}
+
+func sortedCopy(s []string) []string {
+	out := slices.Clone(s)
+	slices.Sort(out)
+	return out
+}
@@ -1,11 +1,24 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+
+	"gateway-service/internal/middlewares"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/grpc/codes"
 )
 
+// HeaderErrorCode carries the canonical codes.Code of the last error
+// response written for a request. middlewares.RequestLog reads it back
+// off the response headers so its slog line can include the code without
+// WriteError having to log anything itself.
+const HeaderErrorCode = "X-Error-Code"
+
 // errorResponse is the standard JSON error body returned for all error
 // conditions. This replaces http.Error (which returns text/plain) so
 // automated webhook clients always receive consistent JSON.
@@ -15,30 +28,150 @@ import (
 //     ensures all API responses, including errors, are JSON-encoded.
 //   - Jaeger's httperr.HandleError (hotrod/pkg/httperr/httperr.go)
 //     which centralizes error-to-HTTP-response conversion.
-//   - ArgoCD's webhook Handler() which uses http.Error for failures —
-//     we improve on this by always returning JSON.
+//   - grpc-gateway's runtime.DefaultHTTPErrorHandler, whose Code/Details
+//     shape this mirrors so a client written against either transport
+//     sees the same error envelope.
 type errorResponse struct {
-	Error  string `json:"error"`
-	Status int    `json:"status"`
+	Error         string `json:"error"`
+	Status        int    `json:"status"`
+	Code          string `json:"code"`
+	Details       []any  `json:"details,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// HTTPError is an error that already knows the HTTP status and gRPC-style
+// code it should be reported as, for handlers whose failure can't be
+// expressed as one of the other error types WriteError recognizes.
+type HTTPError struct {
+	Status  int
+	Code    codes.Code
+	Message string
+	Details []any
+}
+
+func NewHTTPError(status int, code codes.Code, message string) *HTTPError {
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
 }
 
 // writeErrorJSON writes a JSON error response with the given HTTP status
-// code. It replaces every call to http.Error in the webhook handlers so
-// that clients always receive application/json responses.
-//
-// ArgoCD's webhook handler uses http.Error for failures like:
-//
-//	http.Error(w, "Unknown webhook event", http.StatusBadRequest)
-//	http.Error(w, msg, http.StatusBadRequest)
-//
-// This normalizes them all to JSON.
+// code and a code inferred from it. It predates WriteError and remains
+// the quick path for handlers that already know their status code;
+// WriteError is preferred for errors surfaced from deeper in the call
+// stack, where the right status isn't known at the call site.
 func writeErrorJSON(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(errorResponse{
+	writeError(nil, w, errorResponse{
 		Error:  message,
 		Status: statusCode,
-	}); err != nil {
+		Code:   codeForStatus(statusCode).String(),
+	})
+}
+
+// WriteError inspects err via errors.As/errors.Is and writes the JSON
+// error response for it, centralizing the error -> HTTP status / gRPC
+// code mapping that used to be hand-picked at every writeErrorJSON call
+// site:
+//
+//   - *HTTPError: its own Status/Code/Message/Details.
+//   - *protovalidate.ValidationError: 400 INVALID_ARGUMENT, with one
+//     detail per field violation.
+//   - *SchemaValidationError: 400 INVALID_ARGUMENT, with one detail per
+//     JSON Schema violation.
+//   - context.DeadlineExceeded (via errors.Is, so a wrapped ctx.Err()
+//     still matches): 504 DEADLINE_EXCEEDED.
+//   - anything else: 500 INTERNAL.
+func WriteError(r *http.Request, w http.ResponseWriter, err error) {
+	resp := errorResponse{
+		Error:  err.Error(),
+		Status: http.StatusInternalServerError,
+		Code:   codes.Internal.String(),
+	}
+
+	var httpErr *HTTPError
+	var valErr *protovalidate.ValidationError
+	var schemaErr *SchemaValidationError
+
+	switch {
+	case errors.As(err, &httpErr):
+		resp.Status = httpErr.Status
+		resp.Code = httpErr.Code.String()
+		resp.Error = httpErr.Message
+		resp.Details = httpErr.Details
+
+	case errors.As(err, &valErr):
+		resp.Status = http.StatusBadRequest
+		resp.Code = codes.InvalidArgument.String()
+		for _, violation := range valErr.Violations {
+			resp.Details = append(resp.Details, violationDetail{
+				Field:  violation.Proto.GetField().String(),
+				Rule:   violation.Proto.GetConstraintId(),
+				Reason: violation.Proto.GetMessage(),
+			})
+		}
+
+	case errors.As(err, &schemaErr):
+		resp.Status = http.StatusBadRequest
+		resp.Code = codes.InvalidArgument.String()
+		resp.Error = schemaErr.Error()
+		for _, violation := range schemaErr.Violations {
+			resp.Details = append(resp.Details, violationDetail{
+				Field:  violation.Field,
+				Reason: violation.Reason,
+			})
+		}
+
+	case errors.Is(err, context.DeadlineExceeded):
+		resp.Status = http.StatusGatewayTimeout
+		resp.Code = codes.DeadlineExceeded.String()
+	}
+
+	writeError(r, w, resp)
+}
+
+// violationDetail is the JSON shape of a single protovalidate or JSON
+// Schema field violation surfaced in errorResponse.Details.
+type violationDetail struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// codeForStatus maps an HTTP status to the codes.Code a caller of
+// writeErrorJSON would have picked had it been written against WriteError
+// instead, so old and new call sites produce the same Code field.
+func codeForStatus(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// writeError encodes resp as the response body and stamps HeaderErrorCode
+// so middlewares.RequestLog can pick the code back up for its access log.
+func writeError(r *http.Request, w http.ResponseWriter, resp errorResponse) {
+	if resp.CorrelationID == "" && r != nil {
+		resp.CorrelationID = middlewares.GetCorrelationID(r.Context())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(HeaderErrorCode, resp.Code)
+	w.WriteHeader(resp.Status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		// If JSON encoding fails, there's nothing left to do — headers
 		// are already written. Log it for debugging.
 		slog.Error("failed to encode error response", "error", err)
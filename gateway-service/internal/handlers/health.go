@@ -1,20 +1,29 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"gateway-service/internal/config"
 	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
+	"gateway-service/internal/metrics"
 	"log/slog"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 )
 
 // GRPCConnChecker is the subset of grpc.ClientConn needed by the health
 // handler. Keeping it as an interface makes unit testing straightforward.
+// It embeds grpc.ClientConnInterface (rather than just GetState) so a
+// grpc_health_v1.HealthClient can be built on top of it for the
+// orchestrator readiness probe registered in NewHealthHandler.
 type GRPCConnChecker interface {
 	GetState() connectivity.State
+	grpc.ClientConnInterface
 }
 
 // HealthHandler serves liveness and readiness probes.
@@ -32,19 +41,92 @@ type HealthHandler struct {
 	// Once true, readiness always returns 503 so the load balancer
 	// drains traffic before the process exits.
 	terminateRequested atomic.Bool
+
+	// degraded is set when a config hot-reload (see config.Reloader)
+	// fails to apply cleanly. It does not fail readiness — the previous,
+	// known-good config is still in effect — but it's surfaced in the
+	// readiness payload so dashboards can flag it.
+	degraded       atomic.Bool
+	degradedReason atomic.Value // string
+
+	// certsUnavailable is set by certmanager.Manager (via its
+	// SetReadyChangeHook) when it has no valid cert/key pair loaded.
+	// Unlike degraded, this fails readiness outright: with no certificate,
+	// the gateway can't actually serve or dial mTLS traffic, so there's
+	// nothing "last-good" to keep serving.
+	certsUnavailable atomic.Bool
+
+	// probes are additional dependency checks registered via
+	// RegisterProbe — the gRPC orchestrator probe wired below, plus any
+	// SecretManager/Platform probes main.go registers once the Bundle is
+	// available. Each runs (and caches its result) independently, so one
+	// slow dependency can't block the others.
+	probesMu sync.Mutex
+	probes   []*probeEntry
 }
 
 func NewHealthHandler(conn GRPCConnChecker, client pb.OrchestratorClient) *HealthHandler {
 	h := &HealthHandler{conn: conn, client: client}
 	h.available.Store(true) // ready by default; call SetUnavailable during shutdown
+
+	// The orchestrator connectivity probe is always registered (when a
+	// connection is configured) since every deployment depends on it;
+	// SecretManager/Platform probes are added later by the caller, once
+	// a Bundle exists, via RegisterProbe.
+	if conn != nil {
+		h.RegisterProbe(&grpcHealthProbe{conn: conn}, ProbeOpts{
+			Timeout:  DefaultProbeTimeout,
+			CacheTTL: 2 * time.Second,
+			Critical: true,
+		})
+	}
 	return h
 }
 
+// RegisterProbe adds a readiness dependency check. Probes run on every
+// uncached Readiness call; see ProbeOpts.CacheTTL to bound how often an
+// individual probe actually hits its backend under aggressive k8s
+// readiness polling. Safe to call concurrently, including after the
+// handler has started serving.
+func (h *HealthHandler) RegisterProbe(p Probe, opts ProbeOpts) {
+	h.probesMu.Lock()
+	defer h.probesMu.Unlock()
+	h.probes = append(h.probes, &probeEntry{probe: p, opts: opts})
+}
+
 // SetUnavailable marks the server as shutting down. Subsequent
 // readiness probes will return 503 to drain traffic.
 func (h *HealthHandler) SetUnavailable() {
 	h.terminateRequested.Store(true)
 	h.available.Store(false)
+	metrics.SetReady(false)
+}
+
+// SetDegraded marks (or clears, when err is nil) a non-fatal config
+// problem — e.g. a failed hot-reload — so it shows up in the readiness
+// payload without tripping the 503 load-balancer drain path.
+func (h *HealthHandler) SetDegraded(err error) {
+	if err == nil {
+		h.degraded.Store(false)
+		return
+	}
+	h.degradedReason.Store(err.Error())
+	h.degraded.Store(true)
+}
+
+// Reload implements config.Reloadable: a successful config reload clears
+// any previously-recorded degraded state.
+func (h *HealthHandler) Reload(*config.Env) error {
+	h.SetDegraded(nil)
+	return nil
+}
+
+// SetCertsUnavailable marks (or clears) whether certmanager.Manager has a
+// valid cert/key pair loaded. Wired via certmanager.Manager.SetReadyChangeHook in
+// cmd/main.go so readiness fails fast instead of serving with a stale or
+// missing TLS identity.
+func (h *HealthHandler) SetCertsUnavailable(unavailable bool) {
+	h.certsUnavailable.Store(unavailable)
 }
 
 // Liveness returns 200 as long as the process is alive.
@@ -54,58 +136,107 @@ func (h *HealthHandler) Liveness(w http.ResponseWriter, _ *http.Request) {
 	w.Write([]byte("OK"))
 }
 
-
-func (h *HealthHandler) Readiness(w http.ResponseWriter, _ *http.Request) {
+// Readiness runs the shutdown/TLS fast-path guards first, then every
+// registered Probe, and reports a per-probe breakdown in the JSON body —
+// mirroring the dependency-aware readiness endpoints in Bytebase and
+// ArgoCD (each lists its own sub-checks rather than collapsing to a
+// single bool). The HTTP status only drops to 503 when a Critical probe
+// is DOWN; a failing non-critical probe is still visible in the payload
+// but doesn't drain traffic away from an otherwise-healthy instance.
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
 	// 1. Shutdown guard
 	if h.terminateRequested.Load() {
 		writeHealthJSON(w, http.StatusServiceUnavailable, "NOT_READY",
-			"server is terminating and unable to serve requests")
+			"server is terminating and unable to serve requests", nil)
 		return
 	}
 	if !h.available.Load() {
 		writeHealthJSON(w, http.StatusServiceUnavailable, "NOT_READY",
-			"server is not available: it either hasn't started or is restarting")
+			"server is not available: it either hasn't started or is restarting", nil)
 		return
 	}
 
-	// 2. gRPC backend check.
-	if h.conn == nil {
+	// 2. TLS identity check.
+	if h.certsUnavailable.Load() {
 		writeHealthJSON(w, http.StatusServiceUnavailable, "NOT_READY",
-			"gRPC connection not configured")
+			"tls certificate manager has no valid certificate pair loaded", nil)
 		return
 	}
 
-	state := h.conn.GetState()
+	// 3. Dependency probes — orchestrator gRPC, secret managers,
+	// platform drivers, anything else RegisterProbe added.
+	results := h.runProbes(r.Context())
 
-	switch state {
-	case connectivity.Ready, connectivity.Idle:
-		writeHealthJSON(w, http.StatusOK, "READY", "")
+	ready := true
+	for _, res := range results {
+		if res.Critical && res.Status != probeStatusUp {
+			ready = false
+			break
+		}
+	}
 
-	case connectivity.Connecting:
-		writeHealthJSON(w, http.StatusOK, "READY", "orchestrator connecting")
+	if !ready {
+		slog.Warn("readiness check failed", "duration", time.Since(start), "probes", results)
+		writeHealthJSON(w, http.StatusServiceUnavailable, "NOT_READY", h.degradedDetail(), results)
+		return
+	}
 
-	default:
-		// TransientFailure, Shutdown — not ready.
-		detail := "orchestrator connection: " + state.String()
-		slog.Warn("readiness check failed",
-			"state", state.String(),
-			"duration", time.Since(start),
-		)
-		writeHealthJSON(w, http.StatusServiceUnavailable, "NOT_READY", detail)
+	writeHealthJSON(w, http.StatusOK, h.readyStatus(), h.degradedDetail(), results)
+}
+
+// runProbes runs every registered probe (each respecting its own
+// ProbeOpts.CacheTTL) concurrently and returns one probeResult per probe,
+// in registration order.
+func (h *HealthHandler) runProbes(ctx context.Context) []probeResult {
+	h.probesMu.Lock()
+	entries := make([]*probeEntry, len(h.probes))
+	copy(entries, h.probes)
+	h.probesMu.Unlock()
+
+	results := make([]probeResult, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func(i int, entry *probeEntry) {
+			defer wg.Done()
+			results[i] = entry.run(ctx)
+		}(i, entry)
 	}
+	wg.Wait()
+	return results
+}
+
+// readyStatus reports READY, or DEGRADED when a config hot-reload has
+// failed — still 200 OK, since the last-good config is still serving.
+func (h *HealthHandler) readyStatus() string {
+	if h.degraded.Load() {
+		return "DEGRADED"
+	}
+	return "READY"
+}
+
+func (h *HealthHandler) degradedDetail() string {
+	if !h.degraded.Load() {
+		return ""
+	}
+	reason, _ := h.degradedReason.Load().(string)
+	return "config reload degraded: " + reason
 }
 
 // healthResponse is a small JSON envelope for health probes so that
-// monitoring tools get machine-readable output.
+// monitoring tools get machine-readable output. Probes is omitted by the
+// fast-path guards in Readiness (they return before any probe runs).
+// Liveness doesn't use this envelope; it writes a plain-text "OK".
 type healthResponse struct {
-	Status string `json:"status"`
-	Detail string `json:"detail,omitempty"`
+	Status string        `json:"status"`
+	Detail string        `json:"detail,omitempty"`
+	Probes []probeResult `json:"probes,omitempty"`
 }
 
-func writeHealthJSON(w http.ResponseWriter, httpStatus int, status, detail string) {
+func writeHealthJSON(w http.ResponseWriter, httpStatus int, status, detail string, probes []probeResult) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(httpStatus)
-	_ = json.NewEncoder(w).Encode(healthResponse{Status: status, Detail: detail})
-}
\ No newline at end of file
+	_ = json.NewEncoder(w).Encode(healthResponse{Status: status, Detail: detail, Probes: probes})
+}
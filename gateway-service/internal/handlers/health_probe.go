@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Probe is a pluggable readiness dependency check. Register one with
+// HealthHandler.RegisterProbe; it then runs on every Readiness call
+// (subject to its ProbeOpts.CacheTTL).
+type Probe interface {
+	// Name identifies the probe in the readiness JSON payload, e.g.
+	// "orchestrator_grpc" or "secret_manager:vault-prod".
+	Name() string
+
+	// Check reports whether the dependency is reachable. It should be
+	// side-effect-free and cheap — Check runs under ProbeOpts.Timeout,
+	// and a hung probe only blocks its own result, not the others.
+	Check(ctx context.Context) error
+}
+
+// ProbeOpts configures how a registered Probe is run and weighed when
+// deciding overall readiness.
+type ProbeOpts struct {
+	// Timeout bounds a single Check call. <= 0 falls back to
+	// DefaultProbeTimeout.
+	Timeout time.Duration
+
+	// CacheTTL caches the last result for this long before Check runs
+	// again, so an aggressive k8s readinessProbe (every 1-2s) doesn't
+	// hammer the orchestrator/Vault/platform APIs on every poll. <= 0
+	// disables caching — every Readiness call re-runs Check.
+	CacheTTL time.Duration
+
+	// Critical controls whether a DOWN result fails the overall
+	// readiness response (503). A non-critical probe still appears in
+	// the payload but never drains traffic on its own.
+	Critical bool
+}
+
+// DefaultProbeTimeout bounds a Probe.Check call when ProbeOpts.Timeout
+// isn't set, so one hung dependency can't block the readiness response.
+const DefaultProbeTimeout = 2 * time.Second
+
+const (
+	probeStatusUp   = "UP"
+	probeStatusDown = "DOWN"
+)
+
+// probeResult is the JSON shape for one probe in the readiness payload,
+// mirroring the per-component breakdown Bytebase/ArgoCD readiness
+// endpoints report instead of a single collapsed bool.
+type probeResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Critical  bool   `json:"critical"`
+	Cached    bool   `json:"cached,omitempty"`
+}
+
+// probeEntry pairs a registered Probe with its options and the last
+// cached result, the same TTL-cache shape CachingSecretManager uses in
+// front of GetSecret.
+type probeEntry struct {
+	probe Probe
+	opts  ProbeOpts
+
+	mu       sync.Mutex
+	lastRun  time.Time
+	lastErr  error
+	lastTook time.Duration
+}
+
+func (e *probeEntry) run(ctx context.Context) probeResult {
+	if cached, ok := e.cached(); ok {
+		return cached
+	}
+
+	timeout := e.opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := e.probe.Check(checkCtx)
+	took := time.Since(start)
+
+	e.mu.Lock()
+	e.lastRun = time.Now()
+	e.lastErr = err
+	e.lastTook = took
+	e.mu.Unlock()
+
+	return e.toResult(err, took, false)
+}
+
+// cached returns the last result if it's still within ProbeOpts.CacheTTL.
+func (e *probeEntry) cached() (probeResult, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.opts.CacheTTL <= 0 || e.lastRun.IsZero() || time.Since(e.lastRun) >= e.opts.CacheTTL {
+		return probeResult{}, false
+	}
+	return e.toResult(e.lastErr, e.lastTook, true), true
+}
+
+// toResult must be called with e.mu held (or with values already copied
+// out from under the lock, as in run's post-Check update).
+func (e *probeEntry) toResult(err error, took time.Duration, cached bool) probeResult {
+	res := probeResult{
+		Name:      e.probe.Name(),
+		LatencyMS: took.Milliseconds(),
+		Critical:  e.opts.Critical,
+		Cached:    cached,
+	}
+	if err != nil {
+		res.Status = probeStatusDown
+		res.Error = err.Error()
+	} else {
+		res.Status = probeStatusUp
+	}
+	return res
+}
+
+// grpcHealthProbe checks the orchestrator connection via an actual
+// grpc.health.v1.Health/Check RPC rather than trusting connectivity.State
+// alone — the same signal Kubernetes' native gRPC probe and ArgoCD's
+// repo-server readiness rely on, since a channel can report Ready while
+// the remote process itself is unhealthy (e.g. still loading).
+type grpcHealthProbe struct {
+	conn GRPCConnChecker
+}
+
+func (p *grpcHealthProbe) Name() string { return "orchestrator_grpc" }
+
+func (p *grpcHealthProbe) Check(ctx context.Context) error {
+	if state := p.conn.GetState(); state == connectivity.Shutdown {
+		return fmt.Errorf("connection shutdown")
+	}
+
+	resp, err := grpc_health_v1.NewHealthClient(p.conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("health check rpc failed: %w", err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("orchestrator reports status %s", resp.GetStatus())
+	}
+	return nil
+}
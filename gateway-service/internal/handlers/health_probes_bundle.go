@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"gateway-service/internal/models"
+)
+
+// secretManagerPinger is implemented by a models.SecretManager that can
+// report backend reachability without resolving an actual secret (e.g.
+// Vault's sys/health). It's optional: a driver that doesn't implement it
+// is still probed, just shallowly — reaching Bundle.GetSecretManager
+// already exercises the driver's Open/connection setup.
+type secretManagerPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// SecretManagerProbe checks one Bundle-configured SecretManager by name.
+// Register one per entry in Bundle.ListConfiguredSecretManagers().
+type SecretManagerProbe struct {
+	name   string
+	bundle *models.Bundle
+}
+
+// NewSecretManagerProbe builds a Probe for the named secret manager, as
+// configured in the Bundle's CUE config.
+func NewSecretManagerProbe(name string, bundle *models.Bundle) *SecretManagerProbe {
+	return &SecretManagerProbe{name: name, bundle: bundle}
+}
+
+func (p *SecretManagerProbe) Name() string { return "secret_manager:" + p.name }
+
+func (p *SecretManagerProbe) Check(ctx context.Context) error {
+	sm, err := p.bundle.GetSecretManager(ctx, p.name)
+	if err != nil {
+		return fmt.Errorf("secret manager %q: %w", p.name, err)
+	}
+
+	if pinger, ok := sm.(secretManagerPinger); ok {
+		if err := pinger.Ping(ctx); err != nil {
+			return fmt.Errorf("secret manager %q: ping failed: %w", p.name, err)
+		}
+	}
+	return nil
+}
+
+// PlatformProbe checks one Bundle-configured Platform driver by name.
+// Register one per entry in Bundle.ListConfiguredPlatforms().
+type PlatformProbe struct {
+	name   string
+	bundle *models.Bundle
+}
+
+// NewPlatformProbe builds a Probe for the named platform, as configured
+// in the Bundle's CUE config.
+func NewPlatformProbe(name string, bundle *models.Bundle) *PlatformProbe {
+	return &PlatformProbe{name: name, bundle: bundle}
+}
+
+func (p *PlatformProbe) Name() string { return "platform:" + p.name }
+
+// Check reuses Platform.Auth as the lightweight reachability signal —
+// every driver already implements it to validate its configured
+// credentials against the upstream SCM, which is exactly what a platform
+// readiness probe wants without placing any extra API calls.
+func (p *PlatformProbe) Check(ctx context.Context) error {
+	plat, err := p.bundle.GetPlatform(ctx, p.name, "")
+	if err != nil {
+		return fmt.Errorf("platform %q: %w", p.name, err)
+	}
+	if err := plat.Auth(ctx); err != nil {
+		return fmt.Errorf("platform %q: auth check failed: %w", p.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a completed delivery's response is
+// replayed for, when NewWebhookHandler is given ttl <= 0.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// defaultIdempotencyPendingTTL bounds how long a reservation may stay
+// "pending" before it's treated as abandoned (e.g. the request that
+// reserved it was shed with a 503, or the process crashed before it
+// called Complete). Matched to the per-item gRPC
+// deadline WebhookQueueConfig.ItemTimeout defaults to, since that's the
+// longest a legitimate delivery should take to resolve.
+const defaultIdempotencyPendingTTL = 10 * time.Second
+
+// idempotencyWaitTimeout bounds how long a concurrent duplicate blocks on
+// an in-flight delivery's pending sentinel before giving up and telling
+// the caller to retry, rather than risking a double-forward by assuming
+// the original attempt failed.
+const idempotencyWaitTimeout = 5 * time.Second
+
+// IdempotencyRecord is the cached outcome of a webhook delivery, replayed
+// verbatim to duplicate deliveries of the same (platform, delivery-id,
+// body) fingerprint.
+type IdempotencyRecord struct {
+	TaskID  string
+	Status  int
+	Message string
+}
+
+// IdempotencyStore is the pluggable backend behind WebhookHandler's
+// duplicate-delivery detection. Implementations: MemoryIdempotencyStore
+// (in-process LRU) and RedisIdempotencyStore (shared across replicas).
+type IdempotencyStore interface {
+	// Reserve atomically claims key for the caller if no entry exists yet.
+	// ok is true when the caller now owns key and must eventually call
+	// Complete. When ok is false, record is the already-finished result
+	// if one exists, or nil if another caller's delivery is still
+	// pending — the caller should Wait instead of forwarding again.
+	Reserve(ctx context.Context, key string, pendingTTL time.Duration) (record *IdempotencyRecord, ok bool, err error)
+
+	// Wait blocks until the pending entry at key resolves to a completed
+	// record, ctx is cancelled, or timeout elapses, whichever is first.
+	Wait(ctx context.Context, key string, timeout time.Duration) (*IdempotencyRecord, error)
+
+	// Complete stores the final record for key, valid for ttl, and wakes
+	// any callers blocked in Wait on it.
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+}
+
+// computeIdempotencyKey fingerprints a delivery by platform, the
+// platform/caller-supplied delivery ID, and a hash of the raw body, so a
+// retried delivery of the exact same event hashes identically regardless
+// of header casing or transport retries duplicating the connection.
+func computeIdempotencyKey(platformName, deliveryID string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	h := sha256.New()
+	h.Write([]byte(platformName))
+	h.Write([]byte{0})
+	h.Write([]byte(deliveryID))
+	h.Write([]byte{0})
+	h.Write(bodyHash[:])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// deliveryID extracts a caller- or platform-supplied unique ID for this
+// delivery attempt. The generic Idempotency-Key header always wins;
+// otherwise we fall back to each platform's native delivery header, since
+// most webhook sources don't know about Idempotency-Key but already
+// stamp every delivery (including retries) with a stable ID of their own.
+func deliveryID(r *http.Request, platformName string) string {
+	if id := r.Header.Get("Idempotency-Key"); id != "" {
+		return id
+	}
+
+	switch platformName {
+	case "github":
+		return r.Header.Get("X-GitHub-Delivery")
+	case "gitlab":
+		return r.Header.Get("X-Gitlab-Event-UUID")
+	case "bitbucket":
+		return r.Header.Get("X-Request-UUID")
+	default:
+		return ""
+	}
+}
+
+// ── In-memory LRU implementation ────────────────────────────────────────
+
+type memoryIdempotencyEntry struct {
+	key       string
+	record    *IdempotencyRecord // nil while the delivery is still pending
+	expires   time.Time
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (e *memoryIdempotencyEntry) markDone() {
+	e.closeOnce.Do(func() { close(e.done) })
+}
+
+// MemoryIdempotencyStore is a process-local IdempotencyStore bounded by a
+// maximum entry count, evicted least-recently-used. Good enough for a
+// single gateway replica; use RedisIdempotencyStore when multiple
+// replicas must see each other's deliveries.
+type MemoryIdempotencyStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used
+}
+
+// NewMemoryIdempotencyStore creates a store holding at most maxEntries
+// entries (<= 0 defaults to 10000).
+func NewMemoryIdempotencyStore(maxEntries int) *MemoryIdempotencyStore {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &MemoryIdempotencyStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (m *MemoryIdempotencyStore) Reserve(ctx context.Context, key string, pendingTTL time.Duration) (*IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.entries[key]; ok {
+		e := el.Value.(*memoryIdempotencyEntry)
+		if time.Now().Before(e.expires) {
+			m.order.MoveToFront(el)
+			return e.record, false, nil
+		}
+		// Expired entry — a completed record past its TTL, or a pending
+		// sentinel the reserving request never completed (shed with a 503,
+		// or a crash). Evict and fall through to reserve fresh so this
+		// delivery can proceed.
+		m.order.Remove(el)
+		delete(m.entries, key)
+	}
+
+	e := &memoryIdempotencyEntry{
+		key:     key,
+		expires: time.Now().Add(pendingTTL),
+		done:    make(chan struct{}),
+	}
+	el := m.order.PushFront(e)
+	m.entries[key] = el
+	m.evictLocked()
+	return nil, true, nil
+}
+
+func (m *MemoryIdempotencyStore) Wait(ctx context.Context, key string, timeout time.Duration) (*IdempotencyRecord, error) {
+	m.mu.Lock()
+	el, ok := m.entries[key]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("idempotency: key %q not found", key)
+	}
+	e := el.Value.(*memoryIdempotencyEntry)
+	if e.record != nil {
+		m.mu.Unlock()
+		return e.record, nil
+	}
+	done := e.done
+	m.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("idempotency: timed out waiting for key %q", key)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		if e := el.Value.(*memoryIdempotencyEntry); e.record != nil {
+			return e.record, nil
+		}
+	}
+	return nil, fmt.Errorf("idempotency: key %q resolved without a record", key)
+}
+
+func (m *MemoryIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[key]
+	var e *memoryIdempotencyEntry
+	if ok {
+		e = el.Value.(*memoryIdempotencyEntry)
+	} else {
+		e = &memoryIdempotencyEntry{key: key, done: make(chan struct{})}
+		el = m.order.PushFront(e)
+		m.entries[key] = el
+	}
+
+	rec := record
+	e.record = &rec
+	e.expires = time.Now().Add(ttl)
+	e.markDone()
+
+	m.order.MoveToFront(el)
+	m.evictLocked()
+	return nil
+}
+
+// evictLocked drops the least-recently-used entries until the store is
+// back within maxEntries. Callers must hold m.mu.
+func (m *MemoryIdempotencyStore) evictLocked() {
+	for m.order.Len() > m.maxEntries {
+		back := m.order.Back()
+		if back == nil {
+			return
+		}
+		e := back.Value.(*memoryIdempotencyEntry)
+		m.order.Remove(back)
+		delete(m.entries, e.key)
+	}
+}
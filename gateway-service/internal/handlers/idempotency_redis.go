@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPendingSentinel is written by Reserve in place of a record while a
+// delivery is in flight, distinguishing "pending" from "a record that
+// happens to be empty" once it's read back via get.
+const redisPendingSentinel = "__pending__"
+
+// RedisIdempotencyStore shares duplicate-delivery state across every
+// gateway replica, unlike MemoryIdempotencyStore which only sees deliveries
+// routed to the same process. Keys are namespaced under keyPrefix so the
+// gateway can share a Redis instance with other consumers.
+type RedisIdempotencyStore struct {
+	client       *redis.Client
+	keyPrefix    string
+	pollInterval time.Duration
+}
+
+// NewRedisIdempotencyStore wraps client. keyPrefix defaults to
+// "idempotency:" when empty.
+func NewRedisIdempotencyStore(client *redis.Client, keyPrefix string) *RedisIdempotencyStore {
+	if keyPrefix == "" {
+		keyPrefix = "idempotency:"
+	}
+	return &RedisIdempotencyStore{
+		client:       client,
+		keyPrefix:    keyPrefix,
+		pollInterval: 100 * time.Millisecond,
+	}
+}
+
+func (s *RedisIdempotencyStore) redisKey(key string) string {
+	return s.keyPrefix + key
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key string, pendingTTL time.Duration) (*IdempotencyRecord, bool, error) {
+	reserved, err := s.client.SetNX(ctx, s.redisKey(key), redisPendingSentinel, pendingTTL).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("idempotency(redis): reserve %q: %w", key, err)
+	}
+	if reserved {
+		return nil, true, nil
+	}
+
+	record, err := s.get(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, false, nil
+}
+
+// Wait polls at s.pollInterval rather than blocking on a Redis primitive,
+// since keyspace notifications require server-side configuration we can't
+// assume is enabled on every deployment's Redis instance.
+func (s *RedisIdempotencyStore) Wait(ctx context.Context, key string, timeout time.Duration) (*IdempotencyRecord, error) {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		record, err := s.get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			return record, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("idempotency(redis): timed out waiting for key %q", key)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("idempotency(redis): marshal record for %q: %w", key, err)
+	}
+	if err := s.client.Set(ctx, s.redisKey(key), payload, ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency(redis): complete %q: %w", key, err)
+	}
+	return nil
+}
+
+// get returns (nil, nil) when key is still pending, (record, nil) when
+// it's completed, and (nil, nil) when the key doesn't exist at all (the
+// pending sentinel expired — see defaultIdempotencyPendingTTL).
+func (s *RedisIdempotencyStore) get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	value, err := s.client.Get(ctx, s.redisKey(key)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("idempotency(redis): get %q: %w", key, err)
+	}
+	if value == redisPendingSentinel {
+		return nil, nil
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("idempotency(redis): unmarshal record for %q: %w", key, err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
+	"gateway-service/internal/metrics"
+	"gateway-service/internal/middlewares"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebhookQueueConfig configures the bounded buffer and worker pool
+// WebhookQueue uses to decouple HTTP acceptance from the orchestrator RPC.
+//
+// Mirrors the field groupings of middlewares.RateLimiterConfig and
+// middlewares.CircuitBreakerConfig: a handful of tunables with a
+// Default*Config constructor rather than a builder.
+type WebhookQueueConfig struct {
+	// QueueSize is the number of accepted deliveries that may be buffered
+	// waiting for a free worker before new ones are shed with a 503.
+	QueueSize int
+
+	// Workers is the number of goroutines draining the queue concurrently.
+	Workers int
+
+	// ItemTimeout bounds each individual orchestrator RPC a worker issues.
+	ItemTimeout time.Duration
+
+	// DrainTimeout bounds how long Close waits for in-flight and still
+	// buffered deliveries to finish before giving up.
+	DrainTimeout time.Duration
+}
+
+// DefaultWebhookQueueConfig returns sensible defaults for a gateway
+// fronting a single orchestrator instance.
+func DefaultWebhookQueueConfig() WebhookQueueConfig {
+	return WebhookQueueConfig{
+		QueueSize:    500,
+		Workers:      8,
+		ItemTimeout:  10 * time.Second,
+		DrainTimeout: 15 * time.Second,
+	}
+}
+
+type webhookJobKind int
+
+const (
+	webhookJobKindWebhook webhookJobKind = iota
+	webhookJobKindSchema
+)
+
+// webhookJob carries everything a worker needs to deliver one webhook
+// without holding a reference back to the *http.Request that accepted it.
+type webhookJob struct {
+	kind          webhookJobKind
+	webhookReq    *pb.ProcessWebhookRequest
+	schemaReq     *pb.ProcessSchemaWebhookRequest
+	breakerKey    string
+	breakerGen    uint64
+	taskID        string
+	correlationID string
+	requestID     string
+}
+
+// WebhookQueue is a bounded buffered channel plus a worker pool that
+// drains it into the orchestrator gRPC client. Handle/HandleSchemaRegistry
+// enqueue non-blockingly: a full queue is shed with 503 rather than
+// blocking the HTTP goroutine, the same queue-full pattern WebhookHandler's
+// circuit breaker field doc has referenced since it was written.
+type WebhookQueue struct {
+	client  pb.OrchestratorClient
+	breaker *middlewares.CircuitBreaker
+	cfg     WebhookQueueConfig
+
+	jobs     chan webhookJob
+	inFlight atomic.Int64
+	wg       sync.WaitGroup
+}
+
+// NewWebhookQueue starts cfg.Workers goroutines draining the queue and
+// returns immediately. Call Close to drain and stop them.
+func NewWebhookQueue(client pb.OrchestratorClient, breaker *middlewares.CircuitBreaker, cfg WebhookQueueConfig) *WebhookQueue {
+	q := &WebhookQueue{
+		client:  client,
+		breaker: breaker,
+		cfg:     cfg,
+		jobs:    make(chan webhookJob, cfg.QueueSize),
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	return q
+}
+
+// Enqueue offers job to the queue without blocking. It returns false (and
+// the caller should shed the request with 503) when the buffer is full.
+func (q *WebhookQueue) Enqueue(job webhookJob) bool {
+	select {
+	case q.jobs <- job:
+		metrics.SetWebhookQueueDepth(len(q.jobs))
+		return true
+	default:
+		metrics.RecordWebhookQueueDropped()
+		return false
+	}
+}
+
+// Close waits up to cfg.DrainTimeout for buffered and in-flight
+// deliveries to finish before returning. Callers must stop calling
+// Enqueue before calling Close — it closes the underlying channel.
+func (q *WebhookQueue) Close() {
+	close(q.jobs)
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(q.cfg.DrainTimeout):
+		slog.Warn("webhook queue: drain timeout exceeded, remaining deliveries abandoned",
+			"buffered", len(q.jobs), "in_flight", q.inFlight.Load())
+	}
+}
+
+func (q *WebhookQueue) worker() {
+	defer q.wg.Done()
+
+	for job := range q.jobs {
+		metrics.SetWebhookQueueDepth(len(q.jobs))
+		q.deliver(job)
+	}
+}
+
+func (q *WebhookQueue) deliver(job webhookJob) {
+	q.inFlight.Add(1)
+	metrics.SetWebhookQueueInFlight(int(q.inFlight.Load()))
+	defer func() {
+		metrics.SetWebhookQueueInFlight(int(q.inFlight.Add(-1)))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.cfg.ItemTimeout)
+	defer cancel()
+	ctx = injectCorrelationID(ctx, job.correlationID)
+
+	var err error
+	switch job.kind {
+	case webhookJobKindWebhook:
+		_, err = q.client.ProcessWebhook(ctx, job.webhookReq)
+	case webhookJobKindSchema:
+		_, err = q.client.ProcessSchemaWebhook(ctx, job.schemaReq)
+	}
+
+	if err != nil {
+		q.breaker.RecordFailure(job.breakerKey, job.breakerGen)
+		slog.Error("async webhook delivery failed",
+			"task_id", job.taskID, "error", err,
+			"correlation_id", job.correlationID, "request_id", job.requestID)
+		return
+	}
+	q.breaker.RecordSuccess(job.breakerKey, job.breakerGen)
+}
+
+// generateTaskID produces the task ID returned to the caller immediately
+// on 202, before the orchestrator has even seen the delivery. Same
+// construction as middlewares.generateRequestID, prefixed to make it
+// visually distinct in logs and responses.
+func generateTaskID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "task-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed schemas/*.json
+var embeddedSchemasFS embed.FS
+
+// schemaKey identifies a registered schema by platform and webhook event
+// type (e.g. GitHub's "push" header value, GitLab's "Merge Request Hook").
+type schemaKey struct {
+	platform  string
+	eventType string
+}
+
+// SchemaViolation is one structural validation failure, reported as an
+// element of a 400 response's Details list (see errors.go's WriteError)
+// instead of a single message string, so a client can act on exactly
+// which field failed rather than parsing free text.
+type SchemaViolation struct {
+	Field  string
+	Reason string
+}
+
+// SchemaValidationError wraps the SchemaViolations produced by
+// validateWebhookStructure. WriteError recognizes it the same way it
+// already recognizes *protovalidate.ValidationError, turning each
+// violation into a violationDetail in the response body.
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		if v.Field == "" {
+			parts[i] = v.Reason
+			continue
+		}
+		parts[i] = fmt.Sprintf("%s: %s", v.Field, v.Reason)
+	}
+	return "payload validation failed: " + strings.Join(parts, "; ")
+}
+
+// WebhookSchemaRegistry holds compiled JSON Schemas keyed by
+// (platform, event type), consulted by validateWebhookStructure.
+// Platforms register schemas at startup — see LoadEmbedded for the
+// bundled defaults and LoadDir for operator overrides/additions that
+// don't require recompiling the gateway.
+type WebhookSchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[schemaKey]*jsonschema.Schema
+}
+
+func NewWebhookSchemaRegistry() *WebhookSchemaRegistry {
+	return &WebhookSchemaRegistry{schemas: make(map[schemaKey]*jsonschema.Schema)}
+}
+
+// DefaultSchemaRegistry is populated with the bundled GitHub/GitLab/
+// Bitbucket schemas on package init, and optionally extended at process
+// startup by LoadDir(GATEWAY_SCHEMAS_DIR) — see cmd/main.go.
+var DefaultSchemaRegistry = NewWebhookSchemaRegistry()
+
+func init() {
+	if err := DefaultSchemaRegistry.LoadEmbedded(); err != nil {
+		panic(fmt.Sprintf("webhook schema registry: failed to load embedded schemas: %v", err))
+	}
+}
+
+// Register adds or replaces the schema for (platform, eventType).
+func (r *WebhookSchemaRegistry) Register(platform, eventType string, schema *jsonschema.Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[schemaKey{platform, eventType}] = schema
+}
+
+// Lookup returns the schema registered for (platform, eventType), if any.
+// validateWebhookStructure falls back to the legacy top-level-key check
+// when ok is false.
+func (r *WebhookSchemaRegistry) Lookup(platform, eventType string) (*jsonschema.Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.schemas[schemaKey{platform, eventType}]
+	return s, ok
+}
+
+// embeddedSchemaFiles maps (platform, eventType) to its bundled schema
+// file under schemas/. Keyed explicitly rather than derived from
+// eventType, since several platforms' event types (GitLab's "Merge
+// Request Hook", Bitbucket's "repo:push") aren't valid file names.
+var embeddedSchemaFiles = map[schemaKey]string{
+	{"github", "push"}:               "schemas/github_push.json",
+	{"github", "pull_request"}:       "schemas/github_pull_request.json",
+	{"github", "ping"}:               "schemas/github_ping.json",
+	{"gitlab", "Push Hook"}:          "schemas/gitlab_push.json",
+	{"gitlab", "Tag Push Hook"}:      "schemas/gitlab_tag_push.json",
+	{"gitlab", "Merge Request Hook"}: "schemas/gitlab_merge_request.json",
+	{"bitbucket", "repo:push"}:       "schemas/bitbucket_push.json",
+}
+
+// LoadEmbedded (re)loads the schemas bundled into the binary at build
+// time via embeddedSchemasFS.
+func (r *WebhookSchemaRegistry) LoadEmbedded() error {
+	compiler := jsonschema.NewCompiler()
+	for key, path := range embeddedSchemaFiles {
+		data, err := embeddedSchemasFS.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("webhook schema: read embedded %q: %w", path, err)
+		}
+		if err := compiler.AddResource(path, strings.NewReader(string(data))); err != nil {
+			return fmt.Errorf("webhook schema: add resource %q: %w", path, err)
+		}
+		schema, err := compiler.Compile(path)
+		if err != nil {
+			return fmt.Errorf("webhook schema: compile %q: %w", path, err)
+		}
+		r.Register(key.platform, key.eventType, schema)
+	}
+	return nil
+}
+
+// schemaManifestEntry is one line of dir/manifest.json, the data-driven
+// way to register a new platform/event type's schema without recompiling
+// the gateway.
+type schemaManifestEntry struct {
+	Platform  string `json:"platform"`
+	EventType string `json:"event_type"`
+	Schema    string `json:"schema"`
+}
+
+// LoadDir loads schema overrides and additions from dir, driven by
+// dir/manifest.json (a JSON array of schemaManifestEntry). Entries here
+// take precedence over — and may add platforms/event types beyond — the
+// embedded defaults, so an operator can register a new platform by
+// dropping a schema file and a manifest line, without a rebuild.
+func (r *WebhookSchemaRegistry) LoadDir(dir string) error {
+	manifestPath := filepath.Join(dir, "manifest.json")
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("webhook schema: read manifest %q: %w", manifestPath, err)
+	}
+
+	var entries []schemaManifestEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return fmt.Errorf("webhook schema: parse manifest %q: %w", manifestPath, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	for _, entry := range entries {
+		schemaPath := filepath.Join(dir, entry.Schema)
+		data, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return fmt.Errorf("webhook schema: read %q: %w", schemaPath, err)
+		}
+
+		url := "file://" + schemaPath
+		if err := compiler.AddResource(url, strings.NewReader(string(data))); err != nil {
+			return fmt.Errorf("webhook schema: add resource %q: %w", schemaPath, err)
+		}
+		schema, err := compiler.Compile(url)
+		if err != nil {
+			return fmt.Errorf("webhook schema: compile %q: %w", schemaPath, err)
+		}
+
+		r.Register(entry.Platform, entry.EventType, schema)
+	}
+	return nil
+}
+
+// flattenSchemaErrors walks a jsonschema validation error's cause tree
+// down to its leaves, so a deeply nested failure (e.g.
+// pull_request.head.sha) is reported as one violation per leaf rather
+// than one big nested message.
+func flattenSchemaErrors(err error) []SchemaViolation {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []SchemaViolation{{Reason: err.Error()}}
+	}
+
+	var out []SchemaViolation
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			out = append(out, SchemaViolation{
+				Field:  strings.Join(e.InstanceLocation, "."),
+				Reason: e.Message,
+			})
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(ve)
+	return out
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
+	"gateway-service/internal/metrics"
 	"gateway-service/internal/middlewares"
 	"gateway-service/internal/models"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,15 +28,20 @@ const maxWebhookBodySize = 10 << 20
 
 type WebhookHandler struct {
 	bundleService *models.Bundle
-	client        pb.OrchestratorClient
+
+	// queue decouples HTTP acceptance from the orchestrator RPC: a
+	// validated webhook is handed to queue.Enqueue and the handler
+	// replies 202 with a generated task ID immediately, without waiting
+	// for (or blocking on) delivery. See WebhookQueue.
+	queue *WebhookQueue
 
 	// breaker protects the gateway from resource exhaustion when the
 	// orchestrator is slow or unresponsive.
 	//
-	// Without a circuit breaker, each request blocks for up to 10s
-	// (the gRPC timeout), rapidly consuming goroutines and memory.
-	// With it, once consecutive failures exceed the threshold, new
-	// requests fail fast with 503.
+	// Checked here, before a webhook is even enqueued, so an open circuit
+	// sheds load immediately instead of filling the queue with deliveries
+	// certain to fail. WebhookQueue's workers record the RPC outcome back
+	// onto the same breaker once delivery completes.
 	//
 	// Inspired by:
 	//   - ArgoCD's failureRetryRoundTripper (util-kube) which tracks
@@ -46,17 +54,42 @@ type WebhookHandler struct {
 	//           http.Error(w, "Queue is full", http.StatusServiceUnavailable)
 	//       }
 	breaker *middlewares.CircuitBreaker
+
+	// limiter is consulted a second time, after the platform/datasource
+	// is known, via AllowKeyed("platform:<name>", "datasource:<name>") —
+	// on top of the global+per-IP limiting RateLimiter.Middleware already
+	// applied to the whole /webhooks/ subtree — so a noisy source can't
+	// starve the others sharing this gateway.
+	limiter *middlewares.RateLimiter
+
+	// idempotency de-duplicates retried deliveries — see verifyWebhook
+	// and computeIdempotencyKey. Nil disables the check entirely.
+	idempotency IdempotencyStore
+
+	// idempotencyTTL is how long a completed delivery's response is
+	// replayed for before a fresh delivery with the same fingerprint is
+	// treated as new.
+	idempotencyTTL time.Duration
 }
 
 func NewWebhookHandler(
 	bundleService *models.Bundle,
-	client pb.OrchestratorClient,
+	queue *WebhookQueue,
 	breaker *middlewares.CircuitBreaker,
+	limiter *middlewares.RateLimiter,
+	idempotency IdempotencyStore,
+	idempotencyTTL time.Duration,
 ) *WebhookHandler {
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = DefaultIdempotencyTTL
+	}
 	return &WebhookHandler{
-		bundleService: bundleService,
-		client:        client,
-		breaker:       breaker,
+		bundleService:  bundleService,
+		queue:          queue,
+		breaker:        breaker,
+		limiter:        limiter,
+		idempotency:    idempotency,
+		idempotencyTTL: idempotencyTTL,
 	}
 }
 
@@ -99,31 +132,35 @@ func (h *WebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	body, eventType, ok := h.verifyWebhook(w, r, platform, platformName, cid, rid)
+	if !h.allowKeyed(w, r, "platform:"+platformName, "datasource:"+datasourceName) {
+		slog.Warn("rate limit: keyed bucket exhausted",
+			"platform", platformName, "datasource", datasourceName,
+			"correlation_id", cid, "request_id", rid)
+		return
+	}
+
+	body, eventType, idempotencyKey, ok := h.verifyWebhook(w, r, platform, platformName, cid, rid)
 	if !ok {
 		return
 	}
+	metrics.RecordWebhookEvent(platformName, eventType)
 
 	if !platform.IsPushEvent(eventType) && !platform.IsPREvent(eventType) {
 		slog.Info("ignoring unhandled event type",
 			"event_type", eventType, "datasource", datasourceName,
 			"correlation_id", cid, "request_id", rid)
+		h.completeIdempotent(idempotencyKey, IdempotencyRecord{Status: http.StatusOK, Message: "event ignored"})
 		writeJSON(w, http.StatusOK, "", "event ignored")
 		return
 	}
 
-	// 2. Circuit breaker — fail fast when the orchestrator is unresponsive.
-	//
-	// Mirrors ArgoCD's webhook handler queue-full pattern:
-	//   select {
-	//   case a.queue <- payload:
-	//   default:
-	//       http.Error(w, "Queue is full, discarding webhook payload",
-	//           http.StatusServiceUnavailable)
-	//   }
-	// Instead of a queue, we use a state machine that tracks consecutive
-	// failures and opens the circuit after MaxFailures.
-	if !h.breaker.Allow() {
+	// 2. Circuit breaker — fail fast when the orchestrator is unresponsive,
+	// before the delivery ever reaches the queue. Sharded per (platform,
+	// datasource) so a failing datasource doesn't fail-fast every other
+	// one sharing its platform.
+	breakerKey := breakerKey(platformName, datasourceName)
+	allowed, breakerGen := h.breaker.Allow(breakerKey)
+	if !allowed {
 		slog.Warn("circuit breaker open: failing fast",
 			"datasource", datasourceName,
 			"correlation_id", cid, "request_id", rid)
@@ -132,21 +169,40 @@ func (h *WebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 3. Hand off to the async queue. A full buffer is shed with 503
+	// rather than blocking this goroutine on the orchestrator RPC — the
+	// queue-full pattern the breaker field doc above has always described.
 	headers := flattenHeaders(r.Header)
-	resp, err := h.client.ProcessWebhook(ctx, &pb.ProcessWebhookRequest{
-		Platform: platformName, Datasource: datasourceName,
-		RawPayload: string(body), Headers: headers,
+	taskID := generateTaskID()
+	accepted := h.queue.Enqueue(webhookJob{
+		kind: webhookJobKindWebhook,
+		webhookReq: &pb.ProcessWebhookRequest{
+			Platform: platformName, Datasource: datasourceName,
+			RawPayload: string(body), Headers: headers,
+		},
+		breakerKey:    breakerKey,
+		breakerGen:    breakerGen,
+		taskID:        taskID,
+		correlationID: cid,
+		requestID:     rid,
 	})
-	if err != nil {
-		h.breaker.RecordFailure()
-		slog.Error("failed to forward to orchestrator",
-			"error", err, "correlation_id", cid, "request_id", rid)
-		writeErrorJSON(w, http.StatusBadGateway,
-			"Failed to forward to orchestrator")
+	if !accepted {
+		// Leave any idempotency reservation pending rather than completing
+		// it with a failure — it expires on its own (defaultIdempotencyPendingTTL)
+		// so a retried delivery can actually succeed instead of replaying a 503.
+		slog.Warn("webhook queue full: shedding load",
+			"datasource", datasourceName,
+			"correlation_id", cid, "request_id", rid)
+		w.Header().Set("Retry-After", "1")
+		writeErrorJSON(w, http.StatusServiceUnavailable,
+			"webhook queue is full, please retry later")
 		return
 	}
-	h.breaker.RecordSuccess()
-	writeJSON(w, http.StatusAccepted, resp.GetTaskId(), resp.GetMessage())
+
+	h.completeIdempotent(idempotencyKey, IdempotencyRecord{
+		TaskID: taskID, Status: http.StatusAccepted, Message: "webhook accepted for async delivery",
+	})
+	writeJSON(w, http.StatusAccepted, taskID, "webhook accepted for async delivery")
 }
 
 // HandleSchemaRegistry processes schema registry webhooks (POST /webhooks/schema-registry).
@@ -188,21 +244,32 @@ func (h *WebhookHandler) HandleSchemaRegistry(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	body, eventType, ok := h.verifyWebhook(w, r, platform, platformName, cid, rid)
+	if !h.allowKeyed(w, r, "platform:"+platformName, "datasource:schema-registry") {
+		slog.Warn("rate limit: keyed bucket exhausted",
+			"platform", platformName,
+			"correlation_id", cid, "request_id", rid)
+		return
+	}
+
+	body, eventType, idempotencyKey, ok := h.verifyWebhook(w, r, platform, platformName, cid, rid)
 	if !ok {
 		return
 	}
+	metrics.RecordWebhookEvent(platformName, eventType)
 
 	if !platform.IsPushEvent(eventType) {
 		slog.Info("schema registry: ignoring non-push event",
 			"event_type", eventType,
 			"correlation_id", cid, "request_id", rid)
+		h.completeIdempotent(idempotencyKey, IdempotencyRecord{Status: http.StatusOK, Message: "event ignored"})
 		writeJSON(w, http.StatusOK, "", "event ignored")
 		return
 	}
 
 	// Circuit breaker check for schema registry too.
-	if !h.breaker.Allow() {
+	breakerKey := breakerKey(platformName, "schema-registry")
+	allowed, breakerGen := h.breaker.Allow(breakerKey)
+	if !allowed {
 		slog.Warn("circuit breaker open: failing fast (schema-registry)",
 			"correlation_id", cid, "request_id", rid)
 		writeErrorJSON(w, http.StatusServiceUnavailable,
@@ -211,36 +278,144 @@ func (h *WebhookHandler) HandleSchemaRegistry(w http.ResponseWriter, r *http.Req
 	}
 
 	headers := flattenHeaders(r.Header)
-	resp, err := h.client.ProcessSchemaWebhook(ctx, &pb.ProcessSchemaWebhookRequest{
-		Platform: platformName, Repo: repo, Branch: branch,
-		RawPayload: string(body), Headers: headers,
+	taskID := generateTaskID()
+	accepted := h.queue.Enqueue(webhookJob{
+		kind: webhookJobKindSchema,
+		schemaReq: &pb.ProcessSchemaWebhookRequest{
+			Platform: platformName, Repo: repo, Branch: branch,
+			RawPayload: string(body), Headers: headers,
+		},
+		breakerKey:    breakerKey,
+		breakerGen:    breakerGen,
+		taskID:        taskID,
+		correlationID: cid,
+		requestID:     rid,
 	})
-	if err != nil {
-		h.breaker.RecordFailure()
-		slog.Error("failed to forward schema webhook to orchestrator",
-			"error", err, "correlation_id", cid, "request_id", rid)
-		writeErrorJSON(w, http.StatusBadGateway,
-			"failed to forward to orchestrator")
+	if !accepted {
+		slog.Warn("webhook queue full: shedding load (schema-registry)",
+			"correlation_id", cid, "request_id", rid)
+		w.Header().Set("Retry-After", "1")
+		writeErrorJSON(w, http.StatusServiceUnavailable,
+			"webhook queue is full, please retry later")
 		return
 	}
-	h.breaker.RecordSuccess()
-	writeJSON(w, http.StatusAccepted, resp.GetTaskId(), resp.GetMessage())
+
+	h.completeIdempotent(idempotencyKey, IdempotencyRecord{
+		TaskID: taskID, Status: http.StatusAccepted, Message: "webhook accepted for async delivery",
+	})
+	writeJSON(w, http.StatusAccepted, taskID, "webhook accepted for async delivery")
 }
 
 // ── Shared helpers ──────────────────────────────────────────────────────
 
+// allowKeyed consults the per-platform/per-datasource token buckets (see
+// middlewares.RateLimiterConfig.KeyedLimits) now that the caller knows
+// keys, on top of the global+per-IP limiting RateLimiter.Middleware
+// already applied to the whole /webhooks/ subtree. It refreshes the
+// X-RateLimit-* headers to reflect the tightest bucket that matched and,
+// if any bucket rejected the request, writes the 429 response itself.
+// Returns false when the caller should stop processing.
+func (h *WebhookHandler) allowKeyed(w http.ResponseWriter, r *http.Request, keys ...string) bool {
+	if h.limiter == nil {
+		return true
+	}
+
+	allowed, status := h.limiter.AllowKeyed(r.Context(), keys...)
+	if status.Limited {
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(status.Reset))
+	}
+	if allowed {
+		return true
+	}
+
+	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(status.RetryAfter.Seconds())))
+	writeErrorJSON(w, http.StatusTooManyRequests, "rate limit exceeded for "+strings.Join(keys, ", "))
+	return false
+}
+
+// breakerKey builds the middlewares.CircuitBreaker shard key for a
+// platform/datasource pair, mirroring the "platform:<name>"/
+// "datasource:<name>" keys allowKeyed already passes to
+// RateLimiter.AllowKeyed, so a failing datasource only trips its own
+// shard instead of fail-fasting every other one sharing the platform.
+func breakerKey(platformName, datasourceName string) string {
+	return platformName + "/" + datasourceName
+}
+
+// replayIfDuplicate consults h.idempotency for key. If a completed
+// delivery already exists, it replays that response and returns true. If
+// another delivery is still in flight, it blocks (bounded by
+// idempotencyWaitTimeout) for that delivery to finish and replays its
+// result rather than risk forwarding the same event twice. If key is
+// unclaimed, it reserves it for this delivery and returns false — the
+// caller proceeds normally and must eventually call h.idempotency.Complete.
+func (h *WebhookHandler) replayIfDuplicate(w http.ResponseWriter, r *http.Request, key, correlationID, requestID string) bool {
+	record, reserved, err := h.idempotency.Reserve(r.Context(), key, defaultIdempotencyPendingTTL)
+	if err != nil {
+		// Store unavailable — fail open rather than block webhook
+		// delivery on idempotency-store health.
+		slog.Warn("idempotency: reserve failed, proceeding without dedup",
+			"error", err, "correlation_id", correlationID, "request_id", requestID)
+		return false
+	}
+	if reserved {
+		return false
+	}
+
+	if record == nil {
+		ctx, cancel := context.WithTimeout(r.Context(), idempotencyWaitTimeout)
+		defer cancel()
+		record, err = h.idempotency.Wait(ctx, key, idempotencyWaitTimeout)
+		if err != nil {
+			slog.Warn("idempotency: duplicate delivery still in flight",
+				"error", err, "correlation_id", correlationID, "request_id", requestID)
+			writeErrorJSON(w, http.StatusConflict,
+				"a delivery with the same idempotency key is still being processed, please retry")
+			return true
+		}
+	}
+
+	slog.Info("idempotency: replaying cached response for duplicate delivery",
+		"task_id", record.TaskID, "correlation_id", correlationID, "request_id", requestID)
+	writeJSON(w, record.Status, record.TaskID, record.Message)
+	return true
+}
+
+// completeIdempotent stores record for key so a retried delivery of the
+// same event replays it instead of forwarding again. No-op when key is
+// empty (idempotency disabled, or this delivery had no usable delivery
+// ID — see deliveryID) since there is nothing to complete.
+func (h *WebhookHandler) completeIdempotent(key string, record IdempotencyRecord) {
+	if key == "" || h.idempotency == nil {
+		return
+	}
+	if err := h.idempotency.Complete(context.Background(), key, record, h.idempotencyTTL); err != nil {
+		slog.Warn("idempotency: failed to store completed record", "error", err)
+	}
+}
+
 // verifyWebhook performs:
 //  1. Platform header check
 //  2. Size-capped body read (MaxBytesReader)
 //  3. Signature verification
-//  4. JSON well-formedness validation (json.Valid — O(n), zero alloc)
-//  5. Platform-specific structural validation (required fields)
+//  4. Idempotency check — replays a cached response for a duplicate
+//     delivery, or reserves the fingerprint for this one (see
+//     replayIfDuplicate)
+//  5. JSON well-formedness validation (json.Valid — O(n), zero alloc)
+//  6. Platform-specific structural validation (required fields)
 //
-// Returns the body, event type, and true if all checks passed.
+// idempotencyKey is non-empty whenever this delivery reserved a fresh
+// fingerprint in h.idempotency — the caller must eventually pass it to
+// h.idempotency.Complete with the response it ends up sending, so a
+// retried delivery of the same event replays that response instead of
+// forwarding twice. Returns ok=false (with idempotencyKey=="") when the
+// request was already handled as a duplicate and the caller should stop.
 func (h *WebhookHandler) verifyWebhook(
 	w http.ResponseWriter, r *http.Request,
 	platform models.Platform, platformName, correlationID, requestID string,
-) (body []byte, eventType string, ok bool) {
+) (body []byte, eventType string, idempotencyKey string, ok bool) {
 
 	// 1. Platform header check.
 	eventTypeHeader := platform.EventTypeHeader()
@@ -252,7 +427,7 @@ func (h *WebhookHandler) verifyWebhook(
 		writeErrorJSON(w, http.StatusBadRequest,
 			fmt.Sprintf("webhook source mismatch: expected platform %q (header %q missing)",
 				platformName, eventTypeHeader))
-		return nil, "", false
+		return nil, "", "", false
 	}
 
 	// 2. Size-capped body read — ArgoCD's webhook handler uses
@@ -264,7 +439,7 @@ func (h *WebhookHandler) verifyWebhook(
 			"error", err, "correlation_id", correlationID, "request_id", requestID)
 		writeErrorJSON(w, http.StatusRequestEntityTooLarge,
 			"failed to read request body")
-		return nil, "", false
+		return nil, "", "", false
 	}
 
 	// 3. Signature verification.
@@ -276,11 +451,23 @@ func (h *WebhookHandler) verifyWebhook(
 				"platform", platformName,
 				"correlation_id", correlationID, "request_id", requestID)
 			writeErrorJSON(w, http.StatusUnauthorized, "invalid signature")
-			return nil, "", false
+			return nil, "", "", false
 		}
 	}
 
-	// 4. JSON well-formedness check — rejects truncated/corrupted payloads
+	// 4. Idempotency — only after the signature is verified, so an
+	// attacker can't use duplicate-delivery detection to probe fingerprints
+	// for unsigned payloads.
+	if h.idempotency != nil {
+		if id := deliveryID(r, platformName); id != "" {
+			idempotencyKey = computeIdempotencyKey(platformName, id, body)
+			if h.replayIfDuplicate(w, r, idempotencyKey, correlationID, requestID) {
+				return nil, "", "", false
+			}
+		}
+	}
+
+	// 5. JSON well-formedness check — rejects truncated/corrupted payloads
 	// at the gateway before they consume orchestrator resources. Empty
 	// bodies (e.g. GitHub ping events) are allowed through.
 	if len(body) > 0 && !json.Valid(body) {
@@ -290,10 +477,10 @@ func (h *WebhookHandler) verifyWebhook(
 			"body_len", len(body))
 		writeErrorJSON(w, http.StatusBadRequest,
 			"request body is not valid JSON")
-		return nil, "", false
+		return nil, "", "", false
 	}
 
-	// 5. Platform-specific structural validation (improvement #6).
+	// 6. Platform-specific structural validation (improvement #6).
 	//
 	// Goes beyond json.Valid() to verify the payload has the minimal
 	// required fields for the given platform. This prevents junk data
@@ -309,21 +496,50 @@ func (h *WebhookHandler) verifyWebhook(
 				"platform", platformName, "event_type", eventType,
 				"error", err,
 				"correlation_id", correlationID, "request_id", requestID)
-			writeErrorJSON(w, http.StatusBadRequest,
-				fmt.Sprintf("payload validation failed: %s", err.Error()))
-			return nil, "", false
+			WriteError(r, w, err)
+			return nil, "", "", false
 		}
 	}
 
-	return body, eventType, true
+	return body, eventType, idempotencyKey, true
 }
 
 // ── Request body validation (improvement #6) ────────────────────────────
 
-// validateWebhookStructure checks that the JSON payload contains the
-// minimal required top-level keys for the given platform and event type.
+// validateWebhookStructure checks the JSON payload against the schema
+// DefaultSchemaRegistry has registered for (platformName, eventType) —
+// see WebhookSchemaRegistry.LoadEmbedded/LoadDir. A failure is returned
+// as *SchemaValidationError, one violation per invalid field, so the 400
+// response lists every problem instead of just the first.
+//
+// Falls back to validateWebhookStructureTopLevelKeys (a lightweight
+// required-top-level-key check, predating the schema registry) only when
+// no schema is registered for this platform/event type.
+func validateWebhookStructure(platformName, eventType string, body []byte) error {
+	schema, ok := DefaultSchemaRegistry.Lookup(platformName, eventType)
+	if !ok {
+		return validateWebhookStructureTopLevelKeys(platformName, eventType, body)
+	}
+
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return &SchemaValidationError{Violations: []SchemaViolation{
+			{Reason: fmt.Sprintf("failed to parse payload: %v", err)},
+		}}
+	}
+
+	if err := schema.Validate(payload); err != nil {
+		return &SchemaValidationError{Violations: flattenSchemaErrors(err)}
+	}
+	return nil
+}
+
+// validateWebhookStructureTopLevelKeys checks that the JSON payload
+// contains the minimal required top-level keys for the given platform
+// and event type. Predates the JSON-Schema-backed validateWebhookStructure
+// and remains the fallback for any platform/event type without a
+// registered schema.
 //
-// This is a lightweight alternative to full JSON Schema validation.
 // ArgoCD's go-playground/webhooks library validates by deserializing
 // into typed Go structs (e.g. github.PushPayload), which implicitly
 // rejects payloads missing required fields. We do the same check at
@@ -332,11 +548,13 @@ func (h *WebhookHandler) verifyWebhook(
 //
 // ArgoCD's applicationset-webhook (applicationset-webhook/webhook.go)
 // similarly switches on event type to determine what fields to extract.
-func validateWebhookStructure(platformName, eventType string, body []byte) error {
+func validateWebhookStructureTopLevelKeys(platformName, eventType string, body []byte) error {
 	// Parse into a generic map — single allocation, no struct coupling.
 	var payload map[string]json.RawMessage
 	if err := json.Unmarshal(body, &payload); err != nil {
-		return fmt.Errorf("failed to parse payload: %w", err)
+		return &SchemaValidationError{Violations: []SchemaViolation{
+			{Reason: fmt.Sprintf("failed to parse payload: %v", err)},
+		}}
 	}
 
 	var requiredKeys []string
@@ -392,8 +610,10 @@ func validateWebhookStructure(platformName, eventType string, body []byte) error
 
 	for _, key := range requiredKeys {
 		if _, exists := payload[key]; !exists {
-			return fmt.Errorf("missing required field %q for %s/%s event",
-				key, platformName, eventType)
+			return &SchemaValidationError{Violations: []SchemaViolation{{
+				Field:  key,
+				Reason: fmt.Sprintf("missing required field for %s/%s event", platformName, eventType),
+			}}}
 		}
 	}
 	return nil
@@ -6,12 +6,16 @@ import (
 	"strings"
 )
 
+// level is shared by the default handler installed in init() and
+// SetLevelFromString, so LOG_LEVEL can change at runtime (config
+// hot-reload) without rebuilding the handler or restarting the process.
+var level slog.LevelVar
+
 func init() {
-	// Get log level from environment variable (defaults to INFO)
-	level := getLogLevel()
+	level.Set(parseLogLevel(os.Getenv("LOG_LEVEL")))
 
 	opts := &slog.HandlerOptions{
-		Level: level,
+		Level: &level,
 	}
 
 	handler := slog.NewJSONHandler(os.Stdout, opts)
@@ -21,10 +25,14 @@ func init() {
 	slog.SetDefault(logger)
 }
 
-func getLogLevel() slog.Level {
-	levelStr := strings.ToUpper(os.Getenv("LOG_LEVEL"))
+// SetLevelFromString updates the active log level in place. Safe to call
+// concurrently with logging — slog.LevelVar is backed by an atomic int64.
+func SetLevelFromString(levelStr string) {
+	level.Set(parseLogLevel(levelStr))
+}
 
-	switch levelStr {
+func parseLogLevel(levelStr string) slog.Level {
+	switch strings.ToUpper(levelStr) {
 	case "DEBUG":
 		return slog.LevelDebug
 	case "INFO":
@@ -0,0 +1,267 @@
+// Package metrics provides a Prometheus registry and the HTTP/gRPC
+// instrumentation the AdminServer scrapes at /metrics.
+//
+// The internal/monitoring package defines a backend-agnostic
+// MetricProvider abstraction, implemented separately by
+// internal/monitoring/prometheus; this package registers its collectors
+// directly and is the Prometheus backend the AdminServer's doc comment
+// has been promising since it was written.
+package metrics
+
+import (
+	"context"
+	"gateway-service/internal/middlewares"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// Registry is served at /metrics by the AdminServer when
+	// AdminConfig.EnableMetrics is set.
+	Registry = prometheus.NewRegistry()
+
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests processed, labeled by method, path, and status.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	webhookEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_events_total",
+		Help: "Total webhook events received, labeled by platform and event type.",
+	}, []string{"platform", "event_type"})
+
+	grpcClientRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_requests_total",
+		Help: "Total orchestrator RPCs issued by the gateway, labeled by method and result code.",
+	}, []string{"method", "code"})
+
+	grpcClientRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_request_duration_seconds",
+		Help:    "Orchestrator RPC latency in seconds, labeled by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// Ready mirrors HealthHandler's availability state as a gauge so
+	// alerting rules can key off it directly instead of scraping /healthz.
+	Ready = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gateway_ready",
+		Help: "1 if the gateway is currently marked ready to serve traffic, 0 otherwise.",
+	})
+
+	// configReloadsTotal tracks config.Reloader attempts, so a run of
+	// "failure" results pages before anyone notices LOG_LEVEL/TLS/Vault
+	// config silently went stale.
+	configReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "config_reloads_total",
+		Help: "Total config hot-reload attempts, labeled by result.",
+	}, []string{"result"})
+
+	// scmRateLimitHitsTotal counts 429/secondary-rate-limit responses
+	// platform.apiClient absorbed on behalf of a caller, labeled by
+	// platform (github, gitlab, ...). A rising rate here means an org's
+	// repo enumeration is close to getting capped or banned outright.
+	scmRateLimitHitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scm_api_rate_limit_hits_total",
+		Help: "Total rate-limit responses (429, or 403 with X-RateLimit-Remaining: 0) hit calling SCM APIs, labeled by platform.",
+	}, []string{"platform"})
+
+	// scmRetriesTotal counts every retry attempt platform.apiClient made,
+	// whether triggered by a rate limit or a 5xx, labeled by platform.
+	scmRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scm_api_retries_total",
+		Help: "Total retry attempts issued calling SCM APIs, labeled by platform.",
+	}, []string{"platform"})
+
+	// webhookQueueDepth tracks how many accepted deliveries are currently
+	// buffered in handlers.WebhookQueue waiting for a worker.
+	webhookQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "webhook_queue_depth",
+		Help: "Current number of webhook deliveries buffered in the async queue.",
+	})
+
+	// webhookQueueInFlight tracks deliveries a worker is actively sending
+	// to the orchestrator right now.
+	webhookQueueInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "webhook_queue_in_flight",
+		Help: "Current number of webhook deliveries being sent to the orchestrator.",
+	})
+
+	// webhookQueueDroppedTotal counts requests shed with 503 because the
+	// queue was full — the exact "queue is full" path the webhook
+	// handler's circuit breaker comment has always referenced.
+	webhookQueueDroppedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_queue_dropped_total",
+		Help: "Total webhook deliveries rejected with 503 because the async queue was full.",
+	})
+
+	// circuitBreakerStateChangesTotal mirrors middlewares.CircuitBreaker's
+	// slog.Warn state-change line as a counter, labeled by shard key and
+	// the transition, so a breaker flapping open/half-open shows up on a
+	// dashboard instead of only in logs.
+	circuitBreakerStateChangesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_state_changes_total",
+		Help: "Total circuit breaker state transitions, labeled by shard key, from state, and to state.",
+	}, []string{"key", "from", "to"})
+)
+
+func init() {
+	Registry.MustRegister(
+		httpRequestsTotal,
+		httpRequestDuration,
+		webhookEventsTotal,
+		grpcClientRequestsTotal,
+		grpcClientRequestDuration,
+		Ready,
+		configReloadsTotal,
+		scmRateLimitHitsTotal,
+		scmRetriesTotal,
+		webhookQueueDepth,
+		webhookQueueInFlight,
+		webhookQueueDroppedTotal,
+		circuitBreakerStateChangesTotal,
+	)
+	Ready.Set(1)
+}
+
+// Metrics is HTTP middleware recording per-request counters and latency.
+// It's installed next to RequestID in the router's middleware chain so it
+// sees the same request/response lifecycle RequestLog does.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(sw, r)
+
+		status := strconv.Itoa(sw.status)
+		path := routeLabel(r)
+		httpRequestsTotal.WithLabelValues(r.Method, path, status).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, path, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeLabel prefers the ServeMux-matched pattern (e.g. "/webhooks/{datasource}")
+// over the raw URL path so per-tenant paths don't blow up cardinality.
+func routeLabel(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return r.URL.Path
+}
+
+// RecordWebhookEvent increments webhook_events_total for a received event.
+// Called from the webhook handlers once the platform and event type are
+// known, since a generic HTTP middleware can't parse platform-specific
+// event headers itself.
+func RecordWebhookEvent(platform, eventType string) {
+	webhookEventsTotal.WithLabelValues(platform, eventType).Inc()
+}
+
+// SetReady updates the readiness gauge. Called from HealthHandler
+// alongside available.Store so gateway_ready never drifts from the
+// state readiness probes actually report.
+func SetReady(ready bool) {
+	if ready {
+		Ready.Set(1)
+		return
+	}
+	Ready.Set(0)
+}
+
+// RecordConfigReload increments config_reloads_total for a hot-reload
+// attempt (see config.Reloader), labeled "success" or "failure".
+func RecordConfigReload(success bool) {
+	if success {
+		configReloadsTotal.WithLabelValues("success").Inc()
+		return
+	}
+	configReloadsTotal.WithLabelValues("failure").Inc()
+}
+
+// RecordSCMRateLimitHit increments scm_api_rate_limit_hits_total for the
+// given platform. Called by platform.apiClient when a response signals a
+// primary or secondary rate limit.
+func RecordSCMRateLimitHit(platform string) {
+	scmRateLimitHitsTotal.WithLabelValues(platform).Inc()
+}
+
+// RecordSCMRetry increments scm_api_retries_total for the given platform.
+// Called by platform.apiClient for every retry attempt, rate-limit or
+// 5xx alike.
+func RecordSCMRetry(platform string) {
+	scmRetriesTotal.WithLabelValues(platform).Inc()
+}
+
+// RecordCircuitBreakerStateChange increments
+// circuit_breaker_state_changes_total. Wired as
+// middlewares.CircuitBreakerConfig.OnStateChange so every transition the
+// breaker logs also shows up as a metric.
+func RecordCircuitBreakerStateChange(key string, from, to middlewares.CircuitState) {
+	circuitBreakerStateChangesTotal.WithLabelValues(key, from.String(), to.String()).Inc()
+}
+
+// SetWebhookQueueDepth updates webhook_queue_depth. Called by
+// handlers.WebhookQueue after every enqueue/dequeue.
+func SetWebhookQueueDepth(n int) {
+	webhookQueueDepth.Set(float64(n))
+}
+
+// SetWebhookQueueInFlight updates webhook_queue_in_flight. Called by
+// handlers.WebhookQueue's workers as they pick up and finish a delivery.
+func SetWebhookQueueInFlight(n int) {
+	webhookQueueInFlight.Set(float64(n))
+}
+
+// RecordWebhookQueueDropped increments webhook_queue_dropped_total for a
+// delivery shed because handlers.WebhookQueue's buffer was full.
+func RecordWebhookQueueDropped() {
+	webhookQueueDroppedTotal.Inc()
+}
+
+// UnaryClientInterceptor records latency and result code for every
+// orchestrator RPC issued via cmd/main.go's grpc.NewClient, the same way
+// Metrics does for inbound HTTP requests.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		grpcClientRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		grpcClientRequestsTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+		return err
+	}
+}
+
+// statusWriter captures the status code written by downstream handlers.
+// Kept as a package-local copy of middlewares.statusWriter, which is
+// unexported there.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+	}
+	return w.ResponseWriter.Write(b)
+}
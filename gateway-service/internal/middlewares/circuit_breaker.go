@@ -7,7 +7,7 @@ import (
 	"time"
 )
 
-// CircuitState represents the state of a circuit breaker.
+// CircuitState represents the state of a single breaker shard.
 //
 // Modeled after sony/gobreaker's three-state machine and informed by
 // ArgoCD's failureRetryRoundTripper (util-kube/failureretrywrapper.go)
@@ -37,141 +37,413 @@ func (s CircuitState) String() string {
 // request is rejected without calling the backend.
 var ErrCircuitOpen = errors.New("circuit breaker is open")
 
+// Counts is a snapshot of one shard's request/outcome tally since its
+// current generation began (see CircuitBreaker's generation tracking
+// below). Mirrors sony/gobreaker's Counts — ReadyToTrip decides whether
+// to trip purely from these numbers instead of a hard-coded rule, so a
+// predicate can react to interleaved success/failure the way a plain
+// consecutive-failure counter can't.
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+func (c *Counts) onRequest() {
+	c.Requests++
+}
+
+func (c *Counts) onSuccess() {
+	c.TotalSuccesses++
+	c.ConsecutiveSuccesses++
+	c.ConsecutiveFailures = 0
+}
+
+func (c *Counts) onFailure() {
+	c.TotalFailures++
+	c.ConsecutiveFailures++
+	c.ConsecutiveSuccesses = 0
+}
+
+// TripOnConsecutiveFailures returns a ReadyToTrip predicate that trips
+// once n consecutive requests have failed — the breaker's original,
+// simpler behavior, kept available for operators who don't want a ratio.
+func TripOnConsecutiveFailures(n uint32) func(Counts) bool {
+	return func(c Counts) bool {
+		return c.ConsecutiveFailures >= n
+	}
+}
+
+// TripOnFailureRatio returns a ReadyToTrip predicate that trips once at
+// least minRequests have landed in the current generation and the
+// failure ratio among them is at or above ratio. This is the predicate
+// that catches a sick backend failing one call in three — a pattern
+// TripOnConsecutiveFailures can miss indefinitely if successes keep
+// resetting the consecutive-failure count to zero.
+func TripOnFailureRatio(minRequests uint32, ratio float64) func(Counts) bool {
+	return func(c Counts) bool {
+		if c.Requests < minRequests {
+			return false
+		}
+		return float64(c.TotalFailures)/float64(c.Requests) >= ratio
+	}
+}
+
 // CircuitBreakerConfig configures the breaker thresholds.
 //
-// ArgoCD's shouldRetry() in failureRetryRoundTripper uses a simple
-// counter + sleep pattern. We extend that into a proper state machine
-// with timeout-based recovery, similar to sony/gobreaker.
+// Field names and the generation-based Counts tracking follow
+// sony/gobreaker's CircuitBreaker/Settings, which this type had only
+// approximated before with a bucketed rolling window; ArgoCD's
+// shouldRetry() in failureRetryRoundTripper is still the inspiration for
+// classifying errors as worth retrying before they ever reach the
+// breaker (see utils/retry).
 type CircuitBreakerConfig struct {
-	// MaxFailures is the number of consecutive failures before the
-	// circuit transitions from Closed → Open.
-	MaxFailures int
+	// Interval is the period, while Closed, after which a shard's Counts
+	// are reset to zero and a new generation begins. Zero means Counts
+	// are never reset purely by time while Closed — only by a state
+	// transition.
+	Interval time.Duration
+
+	// Timeout is the base duration a shard stays Open before admitting
+	// probe requests in HalfOpen.
+	Timeout time.Duration
+
+	// MaxRequests is the number of requests admitted while a shard is
+	// HalfOpen. The shard closes once that many have all reported
+	// success, or re-opens the moment any one fails. Zero means 1.
+	MaxRequests uint32
+
+	// MaxOpenDuration caps the exponential growth of Timeout across
+	// repeated HalfOpen→Open re-trips, so a shard that keeps failing its
+	// probe doesn't end up waiting hours between attempts.
+	MaxOpenDuration time.Duration
 
-	// OpenTimeout is how long the circuit stays Open before moving
-	// to HalfOpen for a probe request.
-	OpenTimeout time.Duration
+	// ReadyToTrip is called with the shard's Counts after every failure
+	// recorded while Closed; returning true trips the shard to Open. A
+	// nil ReadyToTrip defaults to TripOnConsecutiveFailures(5).
+	ReadyToTrip func(Counts) bool
 
-	// HalfOpenMaxSuccesses is the number of consecutive successes in
-	// HalfOpen required to return to Closed.
-	HalfOpenMaxSuccesses int
+	// OnStateChange, if set, is called in addition to the existing
+	// slog.Warn whenever a shard transitions state, so callers can emit
+	// a metric (counter/gauge) alongside the log line without this
+	// package taking a direct dependency on internal/metrics.
+	OnStateChange func(key string, from, to CircuitState)
 }
 
 // DefaultCircuitBreakerConfig returns sensible defaults for a webhook
 // gateway where the orchestrator timeout is 10s.
 func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
 	return CircuitBreakerConfig{
-		MaxFailures:          5,
-		OpenTimeout:          30 * time.Second,
-		HalfOpenMaxSuccesses: 2,
+		Interval:        60 * time.Second,
+		Timeout:         30 * time.Second,
+		MaxRequests:     2,
+		MaxOpenDuration: 5 * time.Minute,
+		ReadyToTrip:     TripOnFailureRatio(10, 0.5),
 	}
 }
 
-// CircuitBreaker implements a thread-safe three-state circuit breaker.
+// BreakerSnapshot is a point-in-time view of one shard's state, returned
+// by CircuitBreaker.Snapshot for the /debug/breakers admin endpoint.
+type BreakerSnapshot struct {
+	State               string    `json:"state"`
+	TripCount           int64     `json:"trip_count"`
+	CurrentTimeout      string    `json:"current_timeout"`
+	Requests            uint32    `json:"requests"`
+	TotalFailures       uint32    `json:"total_failures"`
+	ConsecutiveFailures uint32    `json:"consecutive_failures"`
+	FailureRatio        float64   `json:"failure_ratio"`
+	LastStateChange     time.Time `json:"last_state_change"`
+}
+
+// shard is the per-key state machine. CircuitBreaker holds one of these
+// per (platform, datasource) key so a failing route for one datasource
+// can't open the circuit for all the others.
+//
+// generation identifies the current Counts "epoch": it increments on
+// every state change and every Interval rollover while Closed. Allow
+// hands its caller the generation the request was admitted under;
+// RecordSuccess/RecordFailure drop the result if the shard has since
+// moved to a new generation (e.g. a HalfOpen probe that was still
+// in-flight when the shard already tripped back to Open from a
+// different probe's failure) instead of letting a stale result corrupt
+// the new generation's Counts.
+type shard struct {
+	mu sync.Mutex
+
+	key        string
+	state      CircuitState
+	generation uint64
+	counts     Counts
+	expiry     time.Time // when Closed Interval elapses or Open Timeout elapses
+
+	currentTimeout  time.Duration
+	lastStateChange time.Time
+	tripCount       int64
+}
+
+// CircuitBreaker is a thread-safe, sharded circuit breaker keyed by an
+// arbitrary string (the webhook handler uses "<platform>/<datasource>").
+// Each key gets its own independent state machine so one noisy datasource
+// tripping its shard doesn't fail-fast requests for every other key.
 //
 // References:
+//   - sony/gobreaker: the generation/Counts/ReadyToTrip state machine
+//     this type now follows, adapted to track one generation per shard
+//     rather than per breaker instance.
 //   - ArgoCD util-kube/failureretrywrapper.go: tracks failure count,
 //     calls shouldRetry() checking IsInternalError/IsTimeout/IsTooManyRequests.
 //   - ArgoCD util-grpc/errors.go: maps gRPC codes to retryable vs non-retryable.
-//   - ArgoCD reposerver NewConnection(): timeout interceptor wraps every
-//     call, similar to how our breaker wraps ProcessWebhook.
 type CircuitBreaker struct {
-	mu               sync.Mutex
-	cfg              CircuitBreakerConfig
-	state            CircuitState
-	consecutiveFails int
-	consecutiveSucc  int
-	lastFailure      time.Time
-	lastStateChange  time.Time
+	cfg CircuitBreakerConfig
+
+	mu     sync.Mutex
+	shards map[string]*shard
 }
 
 func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
 	return &CircuitBreaker{
-		cfg:             cfg,
-		state:           CircuitClosed,
-		lastStateChange: time.Now(),
+		cfg:    cfg,
+		shards: make(map[string]*shard),
+	}
+}
+
+func (cb *CircuitBreaker) timeout() time.Duration {
+	if cb.cfg.Timeout <= 0 {
+		return 30 * time.Second
+	}
+	return cb.cfg.Timeout
+}
+
+func (cb *CircuitBreaker) maxRequests() uint32 {
+	if cb.cfg.MaxRequests == 0 {
+		return 1
+	}
+	return cb.cfg.MaxRequests
+}
+
+func (cb *CircuitBreaker) readyToTrip() func(Counts) bool {
+	if cb.cfg.ReadyToTrip != nil {
+		return cb.cfg.ReadyToTrip
 	}
+	return TripOnConsecutiveFailures(5)
 }
 
-// Allow checks whether a request should be permitted through the breaker.
-// Returns true if the request may proceed, false if it should fail fast.
-func (cb *CircuitBreaker) Allow() bool {
+// shardFor returns the shard for key, creating it lazily on first use —
+// mirrors middlewares.RateLimiter.keyedLimiter.
+func (cb *CircuitBreaker) shardFor(key string) *shard {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	switch cb.state {
-	case CircuitClosed:
-		return true
+	s, ok := cb.shards[key]
+	if ok {
+		return s
+	}
+	now := time.Now()
+	s = &shard{
+		key:             key,
+		state:           CircuitClosed,
+		currentTimeout:  cb.timeout(),
+		lastStateChange: now,
+	}
+	cb.toNewGeneration(s, now)
+	cb.shards[key] = s
+	return s
+}
 
+// currentState re-evaluates s against now, auto-transitioning Open→HalfOpen
+// once Timeout has elapsed, or rolling Closed into a fresh generation once
+// Interval has elapsed. Must be called with s.mu held.
+func (cb *CircuitBreaker) currentState(s *shard, now time.Time) {
+	switch s.state {
+	case CircuitClosed:
+		if !s.expiry.IsZero() && now.After(s.expiry) {
+			cb.toNewGeneration(s, now)
+		}
 	case CircuitOpen:
-		// Check if OpenTimeout has elapsed → transition to HalfOpen.
-		if time.Since(cb.lastStateChange) >= cb.cfg.OpenTimeout {
-			cb.transitionTo(CircuitHalfOpen)
-			return true // allow one probe request
+		if now.After(s.expiry) {
+			cb.setState(s, CircuitHalfOpen, now)
 		}
-		return false
-
-	case CircuitHalfOpen:
-		// In HalfOpen, allow limited requests for probing.
-		return true
 	}
-	return false
 }
 
-// RecordSuccess records a successful call.
-func (cb *CircuitBreaker) RecordSuccess() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+// Allow checks whether a request for key should be permitted through the
+// breaker. ok reports whether the request may proceed; generation must be
+// passed back to the matching RecordSuccess/RecordFailure call so a probe
+// result that arrives after the shard has already moved on is dropped
+// instead of being folded into the wrong generation's Counts.
+func (cb *CircuitBreaker) Allow(key string) (ok bool, generation uint64) {
+	s := cb.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cb.currentState(s, now)
 
-	switch cb.state {
+	switch s.state {
+	case CircuitOpen:
+		return false, s.generation
 	case CircuitHalfOpen:
-		cb.consecutiveSucc++
-		if cb.consecutiveSucc >= cb.cfg.HalfOpenMaxSuccesses {
-			cb.transitionTo(CircuitClosed)
+		if s.counts.Requests >= cb.maxRequests() {
+			return false, s.generation
 		}
-	case CircuitClosed:
-		cb.consecutiveFails = 0
 	}
+
+	s.counts.onRequest()
+	return true, s.generation
 }
 
-// RecordFailure records a failed call.
-func (cb *CircuitBreaker) RecordFailure() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+// RecordSuccess records a successful call for key, admitted under
+// generation (the value Allow returned).
+func (cb *CircuitBreaker) RecordSuccess(key string, generation uint64) {
+	s := cb.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	cb.lastFailure = time.Now()
+	now := time.Now()
+	cb.currentState(s, now)
+	if generation != s.generation {
+		return
+	}
+	s.counts.onSuccess()
 
-	switch cb.state {
+	if s.state == CircuitHalfOpen && s.counts.ConsecutiveSuccesses >= cb.maxRequests() {
+		cb.setState(s, CircuitClosed, now)
+	}
+}
+
+// RecordFailure records a failed call for key, admitted under generation
+// (the value Allow returned).
+func (cb *CircuitBreaker) RecordFailure(key string, generation uint64) {
+	s := cb.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cb.currentState(s, now)
+	if generation != s.generation {
+		return
+	}
+	s.counts.onFailure()
+
+	switch s.state {
 	case CircuitClosed:
-		cb.consecutiveFails++
-		if cb.consecutiveFails >= cb.cfg.MaxFailures {
-			cb.transitionTo(CircuitOpen)
+		if cb.readyToTrip()(s.counts) {
+			cb.setState(s, CircuitOpen, now)
 		}
 	case CircuitHalfOpen:
-		// Any failure in HalfOpen trips back to Open.
-		cb.transitionTo(CircuitOpen)
+		// Any single failed probe re-opens immediately rather than
+		// waiting for the rest of the batch to report back.
+		cb.setState(s, CircuitOpen, now)
 	}
 }
 
-// State returns the current state of the circuit breaker.
-func (cb *CircuitBreaker) State() CircuitState {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
-	return cb.state
-}
-
-// transitionTo changes the breaker state. Must be called with mu held.
-func (cb *CircuitBreaker) transitionTo(newState CircuitState) {
-	if cb.state == newState {
+// setState transitions s to newState, starting a new generation and
+// adjusting the exponential Open backoff. Must be called with s.mu held.
+func (cb *CircuitBreaker) setState(s *shard, newState CircuitState, now time.Time) {
+	if s.state == newState {
 		return
 	}
-	prev := cb.state
-	cb.state = newState
-	cb.lastStateChange = time.Now()
-	cb.consecutiveFails = 0
-	cb.consecutiveSucc = 0
+	prev := s.state
+
+	switch newState {
+	case CircuitOpen:
+		if prev == CircuitHalfOpen {
+			// The probe batch proved the backend is still unhealthy —
+			// back off further rather than re-probing at the same cadence.
+			s.currentTimeout *= 2
+			if cb.cfg.MaxOpenDuration > 0 && s.currentTimeout > cb.cfg.MaxOpenDuration {
+				s.currentTimeout = cb.cfg.MaxOpenDuration
+			}
+		} else {
+			s.currentTimeout = cb.timeout()
+		}
+		s.tripCount++
+	case CircuitClosed:
+		// A full Interval spent Closed already reset currentTimeout
+		// implicitly (no trip happened), but closing from HalfOpen after
+		// a clean probe batch should also forgive the earlier backoff.
+		s.currentTimeout = cb.timeout()
+	}
+
+	s.state = newState
+	s.lastStateChange = now
+	cb.toNewGeneration(s, now)
 
 	slog.Warn("circuit breaker state change",
+		"key", s.key,
 		"from", prev.String(),
 		"to", newState.String(),
 	)
+	if cb.cfg.OnStateChange != nil {
+		cb.cfg.OnStateChange(s.key, prev, newState)
+	}
+}
+
+// toNewGeneration resets Counts and computes the expiry that currentState
+// watches for the shard's new state. Must be called with s.mu held.
+func (cb *CircuitBreaker) toNewGeneration(s *shard, now time.Time) {
+	s.generation++
+	s.counts = Counts{}
+
+	switch s.state {
+	case CircuitClosed:
+		if cb.cfg.Interval > 0 {
+			s.expiry = now.Add(cb.cfg.Interval)
+		} else {
+			s.expiry = time.Time{}
+		}
+	case CircuitOpen:
+		s.expiry = now.Add(s.currentTimeout)
+	default: // CircuitHalfOpen
+		s.expiry = time.Time{}
+	}
+}
+
+// State returns the current state for key.
+func (cb *CircuitBreaker) State(key string) CircuitState {
+	s := cb.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cb.currentState(s, time.Now())
+	return s.state
+}
+
+// Snapshot returns a point-in-time view of every shard that has handled
+// at least one request, keyed the same way callers pass keys to
+// Allow/RecordSuccess/RecordFailure. Backs the /debug/breakers admin
+// endpoint.
+func (cb *CircuitBreaker) Snapshot() map[string]BreakerSnapshot {
+	cb.mu.Lock()
+	keys := make([]string, 0, len(cb.shards))
+	shards := make([]*shard, 0, len(cb.shards))
+	for k, s := range cb.shards {
+		keys = append(keys, k)
+		shards = append(shards, s)
+	}
+	cb.mu.Unlock()
+
+	out := make(map[string]BreakerSnapshot, len(keys))
+	for i, key := range keys {
+		s := shards[i]
+		s.mu.Lock()
+		cb.currentState(s, time.Now())
+		ratio := 0.0
+		if s.counts.Requests > 0 {
+			ratio = float64(s.counts.TotalFailures) / float64(s.counts.Requests)
+		}
+		out[key] = BreakerSnapshot{
+			State:               s.state.String(),
+			TripCount:           s.tripCount,
+			CurrentTimeout:      s.currentTimeout.String(),
+			Requests:            s.counts.Requests,
+			TotalFailures:       s.counts.TotalFailures,
+			ConsecutiveFailures: s.counts.ConsecutiveFailures,
+			FailureRatio:        ratio,
+			LastStateChange:     s.lastStateChange,
+		}
+		s.mu.Unlock()
+	}
+	return out
 }
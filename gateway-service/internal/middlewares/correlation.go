@@ -5,6 +5,11 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+
+	"gateway-service/internal/monitoring"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type contextKey string
@@ -14,24 +19,48 @@ const correlationIDKey contextKey = "correlation-id"
 const (
 	// HeaderCorrelationID is the canonical header name for request tracing.
 	HeaderCorrelationID = "X-Correlation-ID"
+
+	// HeaderTraceID echoes the OTel trace ID carrying this request, so an
+	// operator correlating a webhook 5xx against a trace backend doesn't
+	// have to decode the W3C traceparent header by hand. Empty (and
+	// omitted) when tracing isn't configured — see monitoring.Init.
+	HeaderTraceID = "X-Trace-ID"
 )
 
-// CorrelationID ensures every request carries a unique correlation ID.
-// If the incoming request already has the header, it is reused; otherwise
-// a new random ID is generated. The ID is stored in the request context
-// and echoed back in the response header.
+// CorrelationID ensures every request carries a unique correlation ID and a
+// span linked to any trace the caller started upstream.
+//
+// It first extracts a W3C traceparent/tracestate pair from the incoming
+// headers via monitoring.Propagator, so a span started by an upstream LB or
+// by the orchestrator on a prior hop is continued here rather than starting
+// a new trace. It then falls back to the legacy X-Correlation-ID contract:
+// reusing the header if the caller sent one, generating a random ID
+// otherwise. Both the correlation ID and the resulting OTel trace ID (if
+// any) are recorded as span attributes and echoed back as response
+// headers, so the two identifiers — correlation ID for log greps, trace ID
+// for the trace backend — can always be cross-referenced.
 func CorrelationID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := monitoring.Propagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
 		id := r.Header.Get(HeaderCorrelationID)
 		if id == "" {
 			id = generateID()
 		}
 
+		ctx, span := monitoring.Start(ctx, "gateway.request")
+		defer span.End()
+		span.SetAttributes(attribute.String("correlation.id", id))
+
 		// Store in context for downstream handlers and gRPC metadata.
-		ctx := context.WithValue(r.Context(), correlationIDKey, id)
+		ctx = context.WithValue(ctx, correlationIDKey, id)
 
 		// Echo back to the caller.
 		w.Header().Set(HeaderCorrelationID, id)
+		if traceID := monitoring.TraceIDFromContext(ctx); traceID != "" {
+			span.SetAttributes(attribute.String("trace.id", traceID))
+			w.Header().Set(HeaderTraceID, traceID)
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
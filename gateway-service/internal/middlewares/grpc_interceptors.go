@@ -0,0 +1,205 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC metadata keys mirroring the HTTP headers defined in correlation.go
+// and request_id.go, lower-cased per gRPC metadata convention.
+const (
+	metadataRequestID     = "x-request-id"
+	metadataCorrelationID = "x-correlation-id"
+)
+
+// UnaryServerRecovery returns a unary server interceptor that converts
+// panics into codes.Internal errors instead of crashing the process.
+//
+// This is the gRPC equivalent of the HTTP Recovery middleware: same
+// "log with correlation ID, never let a handler panic take down the
+// server" behavior, applied to the future admin/agent gRPC servers.
+func UnaryServerRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logPanic(ctx, info.FullMethod, rec)
+				err = status.Errorf(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery is the streaming counterpart of UnaryServerRecovery.
+func StreamServerRecovery() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logPanic(ss.Context(), info.FullMethod, rec)
+				err = status.Errorf(codes.Internal, "internal error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func logPanic(ctx context.Context, method string, rec any) {
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("panic", fmt.Sprint(rec)),
+		slog.String("stack", string(debug.Stack())),
+	}
+	if rid := requestIDFromContext(ctx); rid != "" {
+		attrs = append(attrs, slog.String("request_id", rid))
+	}
+	if cid := correlationIDFromContext(ctx); cid != "" {
+		attrs = append(attrs, slog.String("correlation_id", cid))
+	}
+	slog.LogAttrs(ctx, slog.LevelError, "recovered from panic in gRPC handler", attrs...)
+}
+
+// UnaryServerLogging returns a unary server interceptor that logs method,
+// peer, duration, and status code for every RPC, pulling the request ID
+// from incoming metadata the same way RequestLog pulls it from the HTTP
+// context. Errors are logged at Error, everything else at Info.
+func UnaryServerLogging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerLogging is the streaming counterpart of UnaryServerLogging.
+func StreamServerLogging() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logRPC(ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("duration", time.Since(start).String()),
+		slog.String("code", status.Code(err).String()),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if rid := GetRequestID(ctx); rid != "" {
+		attrs = append(attrs, slog.String("request_id", rid))
+	} else if rid := requestIDFromContext(ctx); rid != "" {
+		attrs = append(attrs, slog.String("request_id", rid))
+	}
+	if cid := GetCorrelationID(ctx); cid != "" {
+		attrs = append(attrs, slog.String("correlation_id", cid))
+	} else if cid := correlationIDFromContext(ctx); cid != "" {
+		attrs = append(attrs, slog.String("correlation_id", cid))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		slog.LogAttrs(ctx, slog.LevelError, "grpc call failed", attrs...)
+		return
+	}
+	slog.LogAttrs(ctx, slog.LevelInfo, "grpc call", attrs...)
+}
+
+// UnaryClientMetadataPropagator returns a unary client interceptor that
+// shuttles the request/correlation IDs carried in ctx (set by the HTTP
+// RequestID/CorrelationID middleware) onto outgoing gRPC metadata, so a
+// single inbound webhook can be traced all the way to the orchestrator.
+func UnaryClientMetadataPropagator() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = propagateOutgoing(ctx)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientMetadataPropagator is the streaming counterpart of
+// UnaryClientMetadataPropagator.
+func StreamClientMetadataPropagator() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = propagateOutgoing(ctx)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func propagateOutgoing(ctx context.Context) context.Context {
+	if rid := GetRequestID(ctx); rid != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataRequestID, rid)
+	}
+	if cid := GetCorrelationID(ctx); cid != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataCorrelationID, cid)
+	}
+	return ctx
+}
+
+// UnaryServerMetadataPropagator is the server-side counterpart: it reads
+// X-Request-ID/X-Correlation-ID off incoming gRPC metadata (set by the
+// client interceptor above) and stores them in context using the same
+// keys HTTP handlers use, so downstream code can call GetRequestID /
+// GetCorrelationID regardless of transport.
+func UnaryServerMetadataPropagator() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(contextFromIncoming(ctx), req)
+	}
+}
+
+func contextFromIncoming(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if rid := firstValue(md, metadataRequestID); rid != "" {
+		ctx = context.WithValue(ctx, requestIDKey{}, rid)
+	}
+	if cid := firstValue(md, metadataCorrelationID); cid != "" {
+		ctx = context.WithValue(ctx, correlationIDKey, cid)
+	}
+	return ctx
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// requestIDFromContext/correlationIDFromContext fall back to reading the
+// gRPC incoming metadata directly, for call sites (like the panic logger)
+// that run before UnaryServerMetadataPropagator has had a chance to copy
+// the values into the request-scoped keys.
+func requestIDFromContext(ctx context.Context) string {
+	if rid := GetRequestID(ctx); rid != "" {
+		return rid
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		return firstValue(md, metadataRequestID)
+	}
+	return ""
+}
+
+func correlationIDFromContext(ctx context.Context) string {
+	if cid := GetCorrelationID(ctx); cid != "" {
+		return cid
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		return firstValue(md, metadataCorrelationID)
+	}
+	return ""
+}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"math"
@@ -34,6 +35,13 @@ type RateLimiterConfig struct {
 
 	// CleanupInterval controls how often stale per-IP entries are reaped.
 	CleanupInterval time.Duration
+
+	// KeyedLimits configures an independent token bucket per key, e.g.
+	// "platform:github" or "datasource:orders", so one noisy source can't
+	// starve another sharing the same gateway. Only BucketQPS/BucketSize
+	// are read from each entry's config — see AllowKeyed. A key with no
+	// entry here is unlimited.
+	KeyedLimits map[string]RateLimiterConfig
 }
 
 // DefaultRateLimiterConfig returns production-ready defaults.
@@ -67,6 +75,12 @@ type RateLimiter struct {
 	mu      sync.Mutex
 	perIP   map[string]*ipState
 	closeCh chan struct{}
+
+	// keyedMu/keyedLimiters back AllowKeyed: one token bucket per key in
+	// cfg.KeyedLimits, created lazily on first use rather than eagerly
+	// for every configured key up front.
+	keyedMu       sync.Mutex
+	keyedLimiters map[string]*rate.Limiter
 }
 
 type ipState struct {
@@ -77,10 +91,11 @@ type ipState struct {
 
 func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
 	rl := &RateLimiter{
-		global:  rate.NewLimiter(rate.Limit(cfg.BucketQPS), cfg.BucketSize),
-		cfg:     cfg,
-		perIP:   make(map[string]*ipState),
-		closeCh: make(chan struct{}),
+		global:        rate.NewLimiter(rate.Limit(cfg.BucketQPS), cfg.BucketSize),
+		cfg:           cfg,
+		perIP:         make(map[string]*ipState),
+		closeCh:       make(chan struct{}),
+		keyedLimiters: make(map[string]*rate.Limiter),
 	}
 	if cfg.CleanupInterval > 0 {
 		go rl.cleanup()
@@ -158,6 +173,97 @@ func (rl *RateLimiter) setRateLimitHeaders(w http.ResponseWriter) {
 	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(resetSeconds))
 }
 
+// KeyedLimitStatus reports the state of the tightest bucket consulted by
+// AllowKeyed, so callers can mirror setRateLimitHeaders for keyed limits
+// the same way the global bucket does.
+type KeyedLimitStatus struct {
+	// Limited is false when none of the keys passed have a configured
+	// bucket (KeyedLimits has no entry for any of them) — callers should
+	// not emit X-RateLimit-* headers in that case.
+	Limited    bool
+	Limit      int
+	Remaining  int
+	Reset      int
+	RetryAfter time.Duration
+}
+
+// AllowKeyed checks one independent token bucket per key that has a
+// KeyedLimits entry (keys with no entry are unlimited and skipped). All
+// matching buckets are reserved up front; if any is exhausted, every
+// reservation taken in this call is cancelled so a rejected request never
+// partially drains buckets it didn't actually consume. The returned status
+// reflects the bucket with the least remaining capacity, mirroring
+// setRateLimitHeaders for the global bucket.
+func (rl *RateLimiter) AllowKeyed(ctx context.Context, keys ...string) (bool, KeyedLimitStatus) {
+	type reservation struct {
+		res   *rate.Reservation
+		limit int
+		qps   float64
+	}
+
+	var reservations []reservation
+	status := KeyedLimitStatus{}
+	now := time.Now()
+
+	for _, key := range keys {
+		cfg, ok := rl.cfg.KeyedLimits[key]
+		if !ok {
+			continue
+		}
+		status.Limited = true
+		limiter := rl.keyedLimiter(key, cfg)
+		res := limiter.ReserveN(now, 1)
+		reservations = append(reservations, reservation{res: res, limit: cfg.BucketSize, qps: cfg.BucketQPS})
+
+		remaining := int(limiter.TokensAt(now))
+		if remaining < 0 {
+			remaining = 0
+		}
+		if status.Limit == 0 || remaining < status.Remaining {
+			status.Limit = cfg.BucketSize
+			status.Remaining = remaining
+			deficit := cfg.BucketSize - remaining
+			if deficit > 0 && cfg.BucketQPS > 0 {
+				status.Reset = int(math.Ceil(float64(deficit) / cfg.BucketQPS))
+			} else {
+				status.Reset = 0
+			}
+		}
+
+		if d := res.DelayFrom(now); !res.OK() || d > 0 {
+			if d > status.RetryAfter {
+				status.RetryAfter = d
+			}
+		}
+	}
+
+	if !status.Limited {
+		return true, status
+	}
+
+	allowed := status.RetryAfter == 0
+	if !allowed {
+		for _, r := range reservations {
+			r.res.CancelAt(now)
+		}
+	}
+	return allowed, status
+}
+
+// keyedLimiter returns the token bucket for key, creating it lazily from
+// cfg on first use.
+func (rl *RateLimiter) keyedLimiter(key string, cfg RateLimiterConfig) *rate.Limiter {
+	rl.keyedMu.Lock()
+	defer rl.keyedMu.Unlock()
+
+	if limiter, ok := rl.keyedLimiters[key]; ok {
+		return limiter
+	}
+	limiter := rate.NewLimiter(rate.Limit(cfg.BucketQPS), cfg.BucketSize)
+	rl.keyedLimiters[key] = limiter
+	return limiter
+}
+
 // writeRateLimitError writes a 429 response in JSON format for
 // consistency with the rest of the API (improvement #5).
 func writeRateLimitError(w http.ResponseWriter, message string) {
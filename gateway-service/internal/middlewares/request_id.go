@@ -5,6 +5,9 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 type requestIDKey struct{}
@@ -39,6 +42,13 @@ func RequestID(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
 		w.Header().Set(HeaderRequestID, id)
 
+		// Recorded on whatever span is already in ctx — the outermost
+		// otelhttp middleware's root span, by the time this runs (see
+		// internal.Router.buildMiddlewareChain) — rather than starting
+		// one of its own, the way CorrelationID's "gateway.request" span
+		// does for the correlation ID.
+		oteltrace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
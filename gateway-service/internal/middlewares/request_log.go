@@ -6,6 +6,12 @@ import (
 	"time"
 )
 
+// headerErrorCode mirrors handlers.HeaderErrorCode. It's duplicated
+// rather than imported because middlewares sits below handlers in the
+// dependency graph (handlers already imports middlewares for
+// GetCorrelationID), so importing it back here would be a cycle.
+const headerErrorCode = "X-Error-Code"
+
 // RequestLog logs completed HTTP requests with method, path, status, and
 // duration. Errors (status >= 500) are logged at Error level; client
 // errors (4xx) at Warn; successes are silent to avoid noise.
@@ -19,28 +25,38 @@ func RequestLog(next http.Handler) http.Handler {
 		duration := time.Since(start)
 		cid := GetCorrelationID(r.Context())
 
+		// handlers.WriteError/writeErrorJSON stamp this header with the
+		// gRPC-style code of the error response, if any, so it can ride
+		// along in the access log without RequestLog needing to know
+		// anything about the handlers package's error types.
+		code := w.Header().Get(headerErrorCode)
+
 		switch {
 		case sw.status >= 500:
-			slog.Error("request",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"status", sw.status,
-				"duration", duration,
-				"correlation_id", cid,
-			)
+			logRequest(slog.LevelError, r, sw.status, duration, cid, code)
 		case sw.status >= 400:
-			slog.Warn("request",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"status", sw.status,
-				"duration", duration,
-				"correlation_id", cid,
-			)
+			logRequest(slog.LevelWarn, r, sw.status, duration, cid, code)
 			// 2xx/3xx: silent by default to avoid log noise on health probes.
 		}
 	})
 }
 
+// logRequest emits the access log line at level, including the error
+// code attribute only when the handler set one.
+func logRequest(level slog.Level, r *http.Request, status int, duration time.Duration, correlationID, code string) {
+	attrs := []any{
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", status,
+		"duration", duration,
+		"correlation_id", correlationID,
+	}
+	if code != "" {
+		attrs = append(attrs, "code", code)
+	}
+	slog.Log(r.Context(), level, "request", attrs...)
+}
+
 // statusWriter captures the HTTP status code written by downstream handlers.
 type statusWriter struct {
 	http.ResponseWriter
@@ -4,27 +4,26 @@ import (
 	"context"
 	"fmt"
 	pb "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	"gateway-service/internal/models/secret_managers"
 	"gateway-service/internal/utils"
 	"sync"
 )
 
 type Bundle struct {
-	bundle               *pb.Bundle
-	platformFactory      *PlatformFactory
-	secretManagerFactory *SecretManagerFactory
-	secretRefResolver    *utils.SecretRefResolver
-	platforms            map[string]Platform
-	secretManagers       map[string]SecretManager
-	mu                   sync.RWMutex
+	bundle            *pb.Bundle
+	platformFactory   *PlatformFactory
+	secretRefResolver *utils.SecretRefResolver
+	platforms         map[string]Platform
+	secretManagers    map[string]SecretManager
+	mu                sync.RWMutex
 }
 
 func NewBundle(pbBundle *pb.Bundle) *Bundle {
 	b := &Bundle{
-		bundle:               pbBundle,
-		platformFactory:      New(),
-		secretManagerFactory: NewSecretManagerFactory(),
-		platforms:            make(map[string]Platform),
-		secretManagers:       make(map[string]SecretManager),
+		bundle:          pbBundle,
+		platformFactory: New(),
+		platforms:       make(map[string]Platform),
+		secretManagers:  make(map[string]SecretManager),
 	}
 	b.secretRefResolver = utils.NewSecretRefResolver(
 		func(ctx context.Context, name string) (utils.SecretFetcher, error) {
@@ -136,11 +135,23 @@ func (s *Bundle) GetSecretManager(ctx context.Context, name string) (SecretManag
 		return nil, fmt.Errorf("secret manager %q not found in bundle configuration", name)
 	}
 
-	sm, err := s.secretManagerFactory.GetSecretManager(ctx, config)
+	driver, err := SecretManagerFactory.GetSecretManager(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create secret manager %q: %w", name, err)
 	}
 
+	// Wrap in a TTL cache so a hot-path SecretRefResolver.Resolve doesn't
+	// hit the backing store on every call. Vault gets
+	// secret_managers.CachingManager (TTL + negative-cache + KV v2 ETag
+	// revalidation); every other driver gets the simpler
+	// CachingSecretManager.
+	var sm SecretManager
+	if vault, ok := driver.(*secret_managers.Vault); ok {
+		sm = secret_managers.NewCachingManager(vault, secret_managers.CacheOptionsFromVaultConfig(config.GetVault()))
+	} else {
+		sm = NewCachingSecretManager(driver, DefaultSecretCacheTTL, DefaultSecretCacheTTL/5)
+	}
+
 	s.secretManagers[name] = sm
 	return sm, nil
 }
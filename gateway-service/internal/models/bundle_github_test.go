@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"context"
+	"testing"
+
+	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	pb_gh "gateway-service/internal/gen/proto/go/vartrack/v1/models/platforms"
+	pb_utils "gateway-service/internal/gen/proto/go/vartrack/v1/utils"
+	"gateway-service/internal/models"
+
+	_ "gateway-service/internal/models/platform"
+)
+
+// TestBundleGetPlatform_GitHub guards against GitHub silently falling out
+// of models.Register's map the way it once did — GitLab and Bitbucket were
+// registered there, but nothing ever called models.Register("github", ...),
+// so Bundle.GetPlatform(ctx, "github", ...) always failed with "unknown
+// driver" while the config validated fine and the other two platforms
+// worked.
+func TestBundleGetPlatform_GitHub(t *testing.T) {
+	bundle := &pb_models.Bundle{
+		Platforms: []*pb_models.Platform{
+			{
+				Config: &pb_models.Platform_Github{
+					Github: &pb_gh.GitHub{
+						Name:     "github",
+						Endpoint: "https://api.github.com",
+						Token:    &pb_utils.SecretRef{Source: &pb_utils.SecretRef_Value{Value: "test-token"}},
+					},
+				},
+			},
+		},
+	}
+
+	b := models.NewBundle(bundle)
+	defer b.Close(context.Background())
+
+	plat, err := b.GetPlatform(context.Background(), "github", "")
+	if err != nil {
+		t.Fatalf("GetPlatform(ctx, %q, \"\") returned error: %v", "github", err)
+	}
+
+	if got, want := plat.EventTypeHeader(), "X-GitHub-Event"; got != want {
+		t.Errorf("EventTypeHeader() = %q, want %q", got, want)
+	}
+	if got, want := plat.GetSecret(), "test-token"; got != want {
+		t.Errorf("GetSecret() = %q, want %q", got, want)
+	}
+}
@@ -77,6 +77,10 @@ func GetPlatformName(p *pb_models.Platform) string {
 	switch config := p.Config.(type) {
 	case *pb_models.Platform_Github:
 		return config.Github.Name
+	case *pb_models.Platform_Gitlab:
+		return utils.ResolveTagName("gitlab", config.Gitlab.GetTag())
+	case *pb_models.Platform_Bitbucket:
+		return utils.ResolveTagName("bitbucket", config.Bitbucket.GetTag())
 	default:
 		return ""
 	}
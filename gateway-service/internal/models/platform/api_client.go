@@ -0,0 +1,165 @@
+package platform
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway-service/internal/metrics"
+)
+
+// apiClient wraps http.Client with the retry/backoff behaviour every SCM
+// API call needs: honor a 429/secondary-rate-limit response's Retry-After
+// or X-RateLimit-Reset header instead of hammering the API again
+// immediately, and back off with jitter on a 5xx instead of treating it
+// as terminal. GitLab/Bitbucket/GitHub's Auth/GetRepos route through this
+// instead of a bare *http.Client, so the 100-page repo enumeration in
+// GetRepos doesn't get an org rate-limited or banned.
+type apiClient struct {
+	http     *http.Client
+	platform string // metrics label: "github", "gitlab", "bitbucket"
+
+	maxRetries    int
+	maxRetryDelay time.Duration
+}
+
+// defaultMaxRetries and defaultMaxRetryDelay bound how long a single call
+// can be held retrying: GitHub's primary rate limit resets on an hourly
+// window, so honoring X-RateLimit-Reset literally could mean sleeping for
+// up to an hour — capped here so a caller's own context deadline (or an
+// operator's patience) is the real bound in practice.
+const (
+	defaultMaxRetries    = 5
+	defaultMaxRetryDelay = 60 * time.Second
+)
+
+// newAPIClient wraps httpClient (nil is fine — a zero-value *http.Client
+// is used) with retry/backoff for the named platform.
+func newAPIClient(platform string, httpClient *http.Client) *apiClient {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &apiClient{
+		http:          httpClient,
+		platform:      platform,
+		maxRetries:    defaultMaxRetries,
+		maxRetryDelay: defaultMaxRetryDelay,
+	}
+}
+
+// Do executes req, retrying on a rate-limit or 5xx response up to
+// maxRetries times, honoring req.Context()'s deadline/cancellation while
+// waiting out a retry delay. The final response (successful, terminal
+// error, or retries exhausted) is returned as-is for the caller to
+// inspect the status code the same way it always has.
+func (c *apiClient) Do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !c.shouldRetry(resp) || attempt >= c.maxRetries {
+			return resp, nil
+		}
+
+		delay := c.retryDelay(resp, attempt)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden {
+			metrics.RecordSCMRateLimitHit(c.platform)
+		}
+		metrics.RecordSCMRetry(c.platform)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		// Rewind the request body for the retry. http.NewRequest(WithContext)
+		// populates GetBody automatically for the *bytes.Buffer/*bytes.Reader/
+		// *strings.Reader bodies every platform impl uses, so this is a no-op
+		// for bodyless GETs.
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("scm api client: failed to rewind request body for retry: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
+
+// shouldRetry treats 429 and 5xx as retryable, plus GitHub/GitLab's
+// "secondary rate limit" convention of a 403 with X-RateLimit-Remaining: 0
+// — a bare 403 (e.g. missing scope) is left as a terminal error since
+// retrying it would never succeed.
+func (c *apiClient) shouldRetry(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return true
+	case resp.StatusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay honors Retry-After/X-RateLimit-Reset for a rate-limited
+// response, falling back to exponential backoff with jitter for a 5xx —
+// either way capped at maxRetryDelay.
+func (c *apiClient) retryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden {
+		if d, ok := rateLimitDelay(resp.Header); ok {
+			return capDelay(d, c.maxRetryDelay)
+		}
+	}
+	return capDelay(backoffWithJitter(attempt), c.maxRetryDelay)
+}
+
+// rateLimitDelay parses Retry-After (seconds, or an HTTP-date per RFC
+// 7231) if present, else X-RateLimit-Reset (Unix epoch seconds, GitHub's
+// and GitLab's convention).
+func rateLimitDelay(h http.Header) (time.Duration, bool) {
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(ra); err == nil {
+			return time.Until(t), true
+		}
+	}
+	if reset := h.Get("X-RateLimit-Reset"); reset != "" {
+		if epoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			return time.Until(time.Unix(epoch, 0)), true
+		}
+	}
+	return 0, false
+}
+
+// backoffWithJitter returns 2^attempt seconds plus up to 50% jitter, the
+// same "full exponential, partial jitter" shape as AWS's retry guidance —
+// avoids every concurrent caller retrying in lockstep.
+func backoffWithJitter(attempt int) time.Duration {
+	base := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+	return base + jitter
+}
+
+func capDelay(d, max time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,277 @@
+package platform
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	pb_bb "gateway-service/internal/gen/proto/go/vartrack/v1/models/platforms"
+	"gateway-service/internal/models"
+	"gateway-service/internal/monitoring"
+	"gateway-service/internal/utils"
+	"gateway-service/internal/utils/retry"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Bitbucket implements models.Platform for Bitbucket Cloud. Registered
+// alongside GitLab and GitHub in this package — the one Bundle actually
+// resolves "bitbucket" through, see bundle.go's GetPlatform.
+type Bitbucket struct {
+	cfg      *pb_bb.Bitbucket
+	password string
+	webhook  string
+	client   *http.Client
+
+	// api wraps client with rate-limit/5xx-aware retry and backoff (honors
+	// Retry-After/X-RateLimit-Reset) so a large repo enumeration in
+	// GetRepos doesn't get this app password rate-limited or banned.
+	api *apiClient
+
+	// retryPolicy governs Auth and GetRepos's retry.Do calls, for
+	// transport-level failures (timeouts, DNS) that never make it to api's
+	// in-band rate-limit handling. Defaults to retry.DefaultPolicy() so a
+	// Bitbucket instance that's briefly unreachable doesn't fail a webhook
+	// delivery outright.
+	retryPolicy retry.Policy
+}
+
+func init() {
+	models.Register("bitbucket", func() models.Platform { return &Bitbucket{} })
+}
+
+// SetRetryPolicy overrides the default retry schedule used for outbound
+// Bitbucket API calls. Called by whoever constructs this driver directly —
+// internal.Router exposes the configured policy via
+// WithRetryPolicy/Router.RetryPolicy for exactly this, since
+// models.Platform.Open's signature has no room for it.
+func (b *Bitbucket) SetRetryPolicy(p retry.Policy) {
+	b.retryPolicy = p
+}
+
+func (b *Bitbucket) EventTypeHeader() string {
+	return "X-Event-Key"
+}
+
+func (b *Bitbucket) GetGitScmSignature() string {
+	return "X-Hub-Signature"
+}
+
+func (b *Bitbucket) IsPushEvent(eventType string) bool {
+	return eventType == "repo:push"
+}
+
+func (b *Bitbucket) IsPREvent(eventType string) bool {
+	return strings.HasPrefix(eventType, "pullrequest:")
+}
+
+// VerifyWebhook checks Bitbucket's "sha256=<hex hmac>" signature, the
+// same shape as GitHub's X-Hub-Signature-256.
+func (b *Bitbucket) VerifyWebhook(payload []byte, signatureHeader string) bool {
+	if b.webhook == "" {
+		return false
+	}
+
+	const prefix = "sha256="
+	if !strings.HasPrefix(signatureHeader, prefix) {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(b.webhook))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(signatureHeader, prefix)), []byte(expected)) == 1
+}
+
+// ConstructCloneURL follows Bitbucket's app-password clone form:
+// https://x-token-auth:<app-password>@bitbucket.org/<workspace>/<repo>.git
+func (b *Bitbucket) ConstructCloneURL(repo string) string {
+	fullRepo := repo
+	if !strings.Contains(repo, "/") && b.cfg.GetWorkspace() != "" {
+		fullRepo = fmt.Sprintf("%s/%s", b.cfg.GetWorkspace(), repo)
+	}
+
+	u, _ := url.Parse(b.cfg.GetEndpoint())
+	domain := u.Host
+	if domain == "" {
+		domain = "bitbucket.org"
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	if b.password != "" {
+		return fmt.Sprintf("%s://x-token-auth:%s@%s/%s.git", scheme, b.password, domain, fullRepo)
+	}
+	return fmt.Sprintf("%s://%s/%s.git", scheme, domain, fullRepo)
+}
+
+func (b *Bitbucket) Auth(ctx context.Context) (err error) {
+	ctx, span := monitoring.Start(ctx, "bitbucket.auth")
+	defer span.End()
+	defer func() { span.RecordError(err) }()
+	span.SetAttributes(attribute.String("platform.name", b.cfg.GetName()))
+
+	reqURL := fmt.Sprintf("%s/user", b.baseAPIURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(b.cfg.GetUsername(), b.password)
+
+	var resp *http.Response
+	if err := retry.Do(ctx, b.retryPolicy, func() error {
+		var doErr error
+		resp, doErr = b.api.Do(req)
+		return doErr
+	}); err != nil {
+		return fmt.Errorf("bitbucket connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bitbucket auth failed with status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (b *Bitbucket) Open(ctx context.Context, config *pb_models.Platform, resolver *utils.SecretRefResolver, managerName string) (models.Platform, error) {
+	cfg := config.GetBitbucket()
+	if cfg == nil {
+		return nil, fmt.Errorf("bitbucket driver: configuration is missing or not a Bitbucket type")
+	}
+
+	password, err := resolver.Resolve(ctx, cfg.GetAppPassword(), managerName)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket driver: failed to resolve app password: %w", err)
+	}
+	webhookSecret, err := resolver.Resolve(ctx, cfg.GetWebhookSecret(), managerName)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket driver: failed to resolve webhook secret: %w", err)
+	}
+
+	// TLS verification is on by default; InsecureSkipVerify is an explicit
+	// opt-out (cfg.GetInsecureSkipVerify()), not the negation of a
+	// "please verify" flag — a proto3 bool defaults to false, so a
+	// polarity like !cfg.GetVerifySsl() would silently skip verification
+	// for every bundle that doesn't set it, which is backwards for a
+	// secure-by-default client.
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.GetInsecureSkipVerify()},
+	}
+
+	httpClient := &http.Client{
+		Transport: tr,
+		Timeout:   time.Duration(cfg.GetTimeout()) * time.Second,
+	}
+
+	return &Bitbucket{
+		cfg:         cfg,
+		password:    password,
+		webhook:     webhookSecret,
+		client:      httpClient,
+		api:         newAPIClient("bitbucket", httpClient),
+		retryPolicy: retry.DefaultPolicy(),
+	}, nil
+}
+
+func (b *Bitbucket) Close(_ context.Context) error {
+	if b.client != nil {
+		if tr, ok := b.client.Transport.(*http.Transport); ok {
+			tr.CloseIdleConnections()
+		}
+		b.client = nil
+	}
+	return nil
+}
+
+// GetRepos lists repositories in the configured workspace, filtered by
+// pattern against "workspace/repo_slug". Bitbucket paginates via a "next"
+// URL embedded in the response body rather than a Link header.
+func (b *Bitbucket) GetRepos(ctx context.Context, patterns []string) (repos []string, err error) {
+	ctx, span := monitoring.Start(ctx, "bitbucket.get_repos")
+	defer span.End()
+	defer func() {
+		span.RecordError(err)
+		span.SetAttributes(attribute.Int("repo.count", len(repos)))
+	}()
+	span.SetAttributes(attribute.String("platform.name", b.cfg.GetName()))
+
+	nextURL := fmt.Sprintf("%s/repositories/%s?pagelen=100", b.baseAPIURL(), url.PathEscape(b.cfg.GetWorkspace()))
+
+	resolvedSet := make(map[string]struct{})
+
+	for nextURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(b.cfg.GetUsername(), b.password)
+
+		var resp *http.Response
+		if err := retry.Do(ctx, b.retryPolicy, func() error {
+			var doErr error
+			resp, doErr = b.api.Do(req)
+			return doErr
+		}); err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("bitbucket api error: %s", resp.Status)
+		}
+
+		var page struct {
+			Next   string `json:"next"`
+			Values []struct {
+				FullName string `json:"full_name"`
+			} `json:"values"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			return nil, err
+		}
+
+		for _, repo := range page.Values {
+			for _, pattern := range patterns {
+				matched, err := path.Match(pattern, repo.FullName)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+				}
+				if matched {
+					resolvedSet[repo.FullName] = struct{}{}
+					break
+				}
+			}
+		}
+
+		nextURL = page.Next
+	}
+
+	result := make([]string, 0, len(resolvedSet))
+	for repo := range resolvedSet {
+		result = append(result, repo)
+	}
+	return result, nil
+}
+
+func (b *Bitbucket) GetSecret() string {
+	return b.password
+}
+
+func (b *Bitbucket) baseAPIURL() string {
+	return "https://api.bitbucket.org/2.0"
+}
@@ -0,0 +1,320 @@
+package platform
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	pb_gh "gateway-service/internal/gen/proto/go/vartrack/v1/models/platforms"
+	"gateway-service/internal/models"
+	"gateway-service/internal/monitoring"
+	"gateway-service/internal/utils"
+	"gateway-service/internal/utils/retry"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GitHub implements models.Platform for github.com and GitHub Enterprise.
+// It lives alongside GitLab and Bitbucket in this package — the one
+// Bundle actually resolves "github" through, see bundle.go's GetPlatform.
+type GitHub struct {
+	cfg     *pb_gh.GitHub
+	token   string
+	webhook string
+	client  *http.Client
+
+	// api wraps client with rate-limit/5xx-aware retry and backoff (honors
+	// Retry-After/X-RateLimit-Reset) so a paginated org repo enumeration
+	// in GetRepos doesn't get this token rate-limited or banned outright.
+	api *apiClient
+
+	// retryPolicy governs Auth and GetRepos's retry.Do calls, for
+	// transport-level failures (timeouts, DNS) that never make it to api's
+	// in-band rate-limit handling. Defaults to retry.DefaultPolicy() so a
+	// GitHub instance that's briefly unreachable doesn't fail a webhook
+	// delivery outright.
+	retryPolicy retry.Policy
+}
+
+func init() {
+	models.Register("github", func() models.Platform { return &GitHub{} })
+}
+
+// SetRetryPolicy overrides the default retry schedule used for outbound
+// GitHub API calls. Called by whoever constructs this driver directly —
+// internal.Router exposes the configured policy via
+// WithRetryPolicy/Router.RetryPolicy for exactly this, since
+// models.Platform.Open's signature has no room for it.
+func (g *GitHub) SetRetryPolicy(p retry.Policy) {
+	g.retryPolicy = p
+}
+
+func (g *GitHub) EventTypeHeader() string {
+	return "X-GitHub-Event"
+}
+
+func (g *GitHub) GetGitScmSignature() string {
+	return "X-Hub-Signature-256"
+}
+
+func (g *GitHub) IsPushEvent(eventType string) bool {
+	return eventType == "push"
+}
+
+func (g *GitHub) IsPREvent(eventType string) bool {
+	return eventType == "pull_request"
+}
+
+// VerifyWebhook checks GitHub's "sha256=<hex hmac>" signature header,
+// the same shape as Bitbucket Server's self-hosted X-Hub-Signature.
+func (g *GitHub) VerifyWebhook(payload []byte, signatureHeader string) bool {
+	if g.webhook == "" {
+		return false
+	}
+
+	const prefix = "sha256="
+	if !strings.HasPrefix(signatureHeader, prefix) {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(g.webhook))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(signatureHeader, prefix)), []byte(expected)) == 1
+}
+
+// ConstructCloneURL follows GitHub's PAT clone form:
+// https://x-access-token:<token>@github.com/<repo>.git
+func (g *GitHub) ConstructCloneURL(repo string) string {
+	fullRepo := repo
+	if !strings.Contains(repo, "/") {
+		owner := g.cfg.GetOrgName()
+		if owner == "" {
+			owner = g.cfg.GetUsername()
+		}
+		if owner != "" {
+			fullRepo = fmt.Sprintf("%s/%s", owner, repo)
+		}
+	}
+
+	u, _ := url.Parse(g.cfg.GetEndpoint())
+	domain := u.Host
+	if domain == "" {
+		domain = "github.com"
+	}
+
+	if g.cfg.GetProtocol() == "ssh" {
+		return fmt.Sprintf("git@%s:%s.git", domain, fullRepo)
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	if g.token != "" {
+		return fmt.Sprintf("%s://x-access-token:%s@%s/%s.git", scheme, g.token, domain, fullRepo)
+	}
+	return fmt.Sprintf("%s://%s/%s.git", scheme, domain, fullRepo)
+}
+
+func (g *GitHub) Auth(ctx context.Context) (err error) {
+	ctx, span := monitoring.Start(ctx, "github.auth")
+	defer span.End()
+	defer func() { span.RecordError(err) }()
+	span.SetAttributes(attribute.String("platform.name", g.cfg.GetName()))
+
+	reqURL := fmt.Sprintf("%s/user", g.baseAPIURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", g.token))
+	}
+
+	var resp *http.Response
+	if err := retry.Do(ctx, g.retryPolicy, func() error {
+		var doErr error
+		resp, doErr = g.api.Do(req)
+		return doErr
+	}); err != nil {
+		return fmt.Errorf("github connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github auth failed with status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (g *GitHub) Open(ctx context.Context, config *pb_models.Platform, resolver *utils.SecretRefResolver, managerName string) (models.Platform, error) {
+	cfg := config.GetGithub()
+	if cfg == nil {
+		return nil, fmt.Errorf("github driver: configuration is missing or not a GitHub type")
+	}
+
+	token, err := resolver.Resolve(ctx, cfg.GetToken(), managerName)
+	if err != nil {
+		return nil, fmt.Errorf("github driver: failed to resolve token: %w", err)
+	}
+	webhookSecret, err := resolver.Resolve(ctx, cfg.GetWebhookSecret(), managerName)
+	if err != nil {
+		return nil, fmt.Errorf("github driver: failed to resolve webhook secret: %w", err)
+	}
+
+	// TLS verification is on by default; InsecureSkipVerify is an explicit
+	// opt-out (cfg.GetInsecureSkipVerify()), not the negation of a
+	// "please verify" flag — a proto3 bool defaults to false, so a
+	// polarity like !cfg.GetVerifySsl() would silently skip verification
+	// for every bundle that doesn't set it, which is backwards for a
+	// secure-by-default client.
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.GetInsecureSkipVerify()},
+	}
+
+	httpClient := &http.Client{
+		Transport: tr,
+		Timeout:   time.Duration(cfg.GetTimeout()) * time.Second,
+	}
+
+	return &GitHub{
+		cfg:         cfg,
+		token:       token,
+		webhook:     webhookSecret,
+		client:      httpClient,
+		api:         newAPIClient("github", httpClient),
+		retryPolicy: retry.DefaultPolicy(),
+	}, nil
+}
+
+func (g *GitHub) Close(_ context.Context) error {
+	if g.client != nil {
+		if tr, ok := g.client.Transport.(*http.Transport); ok {
+			tr.CloseIdleConnections()
+		}
+		g.client = nil
+	}
+	return nil
+}
+
+// GetRepos lists repositories visible to the token, filtered by pattern
+// against "owner/repo" — the same full_name shape GitLab's "namespace/path"
+// and Bitbucket's "workspace/repo_slug" mirror. GitHub paginates via the
+// RFC5988 Link header, same as GitLab.
+func (g *GitHub) GetRepos(ctx context.Context, patterns []string) (repos []string, err error) {
+	ctx, span := monitoring.Start(ctx, "github.get_repos")
+	defer span.End()
+	defer func() {
+		span.RecordError(err)
+		span.SetAttributes(attribute.Int("repo.count", len(repos)))
+	}()
+	span.SetAttributes(attribute.String("platform.name", g.cfg.GetName()))
+
+	apiBase := g.baseAPIURL()
+
+	var nextURL string
+	if org := g.cfg.GetOrgName(); org != "" {
+		nextURL = fmt.Sprintf("%s/orgs/%s/repos?per_page=100", apiBase, url.PathEscape(org))
+	} else {
+		nextURL = fmt.Sprintf("%s/user/repos?per_page=100", apiBase)
+	}
+
+	resolvedSet := make(map[string]struct{})
+
+	for nextURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if g.token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", g.token))
+		}
+
+		var resp *http.Response
+		if err := retry.Do(ctx, g.retryPolicy, func() error {
+			var doErr error
+			resp, doErr = g.api.Do(req)
+			return doErr
+		}); err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("github api error: %s", resp.Status)
+		}
+
+		var page []struct {
+			FullName string `json:"full_name"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			return nil, err
+		}
+
+		for _, repo := range page {
+			for _, pattern := range patterns {
+				matched, err := path.Match(pattern, repo.FullName)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+				}
+				if matched {
+					resolvedSet[repo.FullName] = struct{}{}
+					break
+				}
+			}
+		}
+
+		nextURL = g.getNextPageURL(resp.Header.Get("Link"))
+	}
+
+	result := make([]string, 0, len(resolvedSet))
+	for repo := range resolvedSet {
+		result = append(result, repo)
+	}
+	return result, nil
+}
+
+func (g *GitHub) GetSecret() string {
+	return g.token
+}
+
+func (g *GitHub) getNextPageURL(linkHeader string) string {
+	if linkHeader == "" {
+		return ""
+	}
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(strings.TrimSpace(link), ";")
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) == `rel="next"` {
+			return strings.Trim(parts[0], "<>")
+		}
+	}
+	return ""
+}
+
+// baseAPIURL resolves github.com vs. a GitHub Enterprise instance,
+// appending the v3 API prefix the same way GitLab's baseAPIURL appends v4.
+func (g *GitHub) baseAPIURL() string {
+	endpoint := strings.TrimSuffix(g.cfg.GetEndpoint(), "/")
+	if endpoint == "" || strings.Contains(endpoint, "github.com") {
+		return "https://api.github.com"
+	}
+	if !strings.Contains(endpoint, "/api/v3") {
+		return endpoint + "/api/v3"
+	}
+	return endpoint
+}
@@ -0,0 +1,294 @@
+package platform
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	pb_gl "gateway-service/internal/gen/proto/go/vartrack/v1/models/platforms"
+	"gateway-service/internal/models"
+	"gateway-service/internal/monitoring"
+	"gateway-service/internal/utils"
+	"gateway-service/internal/utils/retry"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GitLab implements models.Platform for both gitlab.com and self-hosted
+// instances. It's the one driver Bundle actually resolves "gitlab"
+// through — see bundle.go's GetPlatform.
+type GitLab struct {
+	cfg     *pb_gl.GitLab
+	token   string
+	webhook string
+	client  *http.Client
+
+	// api wraps client with rate-limit/5xx-aware retry and backoff (honors
+	// Retry-After/X-RateLimit-Reset) so a 100-page project enumeration in
+	// GetRepos doesn't get this token rate-limited or banned outright.
+	api *apiClient
+
+	// retryPolicy governs Auth and GetRepos's retry.Do calls, for
+	// transport-level failures (timeouts, DNS) that never make it to api's
+	// in-band rate-limit handling. Defaults to retry.DefaultPolicy() so a
+	// GitLab instance that's briefly unreachable doesn't fail a webhook
+	// delivery outright.
+	retryPolicy retry.Policy
+}
+
+func init() {
+	models.Register("gitlab", func() models.Platform { return &GitLab{} })
+}
+
+// SetRetryPolicy overrides the default retry schedule used for outbound
+// GitLab API calls. Called by whoever constructs this driver directly —
+// internal.Router exposes the configured policy via
+// WithRetryPolicy/Router.RetryPolicy for exactly this, since
+// models.Platform.Open's signature has no room for it.
+func (g *GitLab) SetRetryPolicy(p retry.Policy) {
+	g.retryPolicy = p
+}
+
+func (g *GitLab) EventTypeHeader() string {
+	return "X-Gitlab-Event"
+}
+
+func (g *GitLab) GetGitScmSignature() string {
+	return "X-Gitlab-Token"
+}
+
+func (g *GitLab) IsPushEvent(eventType string) bool {
+	return eventType == "Push Hook"
+}
+
+func (g *GitLab) IsPREvent(eventType string) bool {
+	return eventType == "Merge Request Hook"
+}
+
+// VerifyWebhook checks GitLab's shared-secret token, sent verbatim in
+// X-Gitlab-Token rather than as an HMAC of the payload.
+func (g *GitLab) VerifyWebhook(_ []byte, signatureHeader string) bool {
+	if g.webhook == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(signatureHeader), []byte(g.webhook)) == 1
+}
+
+// ConstructCloneURL follows GitLab's documented "deploy token"/PAT clone
+// form: https://oauth2:<token>@gitlab.com/<repo>.git
+func (g *GitLab) ConstructCloneURL(repo string) string {
+	fullRepo := repo
+	if !strings.Contains(repo, "/") && g.cfg.GetGroupId() != "" {
+		fullRepo = fmt.Sprintf("%s/%s", g.cfg.GetGroupId(), repo)
+	}
+
+	u, _ := url.Parse(g.cfg.GetEndpoint())
+	domain := u.Host
+	if domain == "" {
+		domain = "gitlab.com"
+	}
+
+	if g.cfg.GetProtocol() == "ssh" {
+		return fmt.Sprintf("git@%s:%s.git", domain, fullRepo)
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	if g.token != "" {
+		return fmt.Sprintf("%s://oauth2:%s@%s/%s.git", scheme, g.token, domain, fullRepo)
+	}
+	return fmt.Sprintf("%s://%s/%s.git", scheme, domain, fullRepo)
+}
+
+func (g *GitLab) Auth(ctx context.Context) (err error) {
+	ctx, span := monitoring.Start(ctx, "gitlab.auth")
+	defer span.End()
+	defer func() { span.RecordError(err) }()
+	span.SetAttributes(attribute.String("platform.name", g.cfg.GetName()))
+
+	reqURL := fmt.Sprintf("%s/user", g.baseAPIURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+
+	var resp *http.Response
+	if err := retry.Do(ctx, g.retryPolicy, func() error {
+		var doErr error
+		resp, doErr = g.api.Do(req)
+		return doErr
+	}); err != nil {
+		return fmt.Errorf("gitlab connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitlab auth failed with status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (g *GitLab) Open(ctx context.Context, config *pb_models.Platform, resolver *utils.SecretRefResolver, managerName string) (models.Platform, error) {
+	cfg := config.GetGitlab()
+	if cfg == nil {
+		return nil, fmt.Errorf("gitlab driver: configuration is missing or not a GitLab type")
+	}
+
+	token, err := resolver.Resolve(ctx, cfg.GetToken(), managerName)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab driver: failed to resolve token: %w", err)
+	}
+	webhookSecret, err := resolver.Resolve(ctx, cfg.GetWebhookToken(), managerName)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab driver: failed to resolve webhook token: %w", err)
+	}
+
+	// TLS verification is on by default; InsecureSkipVerify is an explicit
+	// opt-out (cfg.GetInsecureSkipVerify()), not the negation of a
+	// "please verify" flag — a proto3 bool defaults to false, so a
+	// polarity like !cfg.GetVerifySsl() would silently skip verification
+	// for every bundle that doesn't set it, which is backwards for a
+	// secure-by-default client.
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.GetInsecureSkipVerify()},
+	}
+
+	httpClient := &http.Client{
+		Transport: tr,
+		Timeout:   time.Duration(cfg.GetTimeout()) * time.Second,
+	}
+
+	return &GitLab{
+		cfg:         cfg,
+		token:       token,
+		webhook:     webhookSecret,
+		client:      httpClient,
+		api:         newAPIClient("gitlab", httpClient),
+		retryPolicy: retry.DefaultPolicy(),
+	}, nil
+}
+
+func (g *GitLab) Close(_ context.Context) error {
+	if g.client != nil {
+		if tr, ok := g.client.Transport.(*http.Transport); ok {
+			tr.CloseIdleConnections()
+		}
+		g.client = nil
+	}
+	return nil
+}
+
+// GetRepos lists projects visible to the token, filtered by pattern
+// against "namespace/path" — the GitLab equivalent of GitHub's
+// full_name. GitLab paginates via the same RFC5988 Link header as GitHub.
+func (g *GitLab) GetRepos(ctx context.Context, patterns []string) (repos []string, err error) {
+	ctx, span := monitoring.Start(ctx, "gitlab.get_repos")
+	defer span.End()
+	defer func() {
+		span.RecordError(err)
+		span.SetAttributes(attribute.Int("repo.count", len(repos)))
+	}()
+	span.SetAttributes(attribute.String("platform.name", g.cfg.GetName()))
+
+	apiBase := g.baseAPIURL()
+
+	var nextURL string
+	if group := g.cfg.GetGroupId(); group != "" {
+		nextURL = fmt.Sprintf("%s/groups/%s/projects?per_page=100", apiBase, url.PathEscape(group))
+	} else {
+		nextURL = fmt.Sprintf("%s/projects?membership=true&per_page=100", apiBase)
+	}
+
+	resolvedSet := make(map[string]struct{})
+
+	for nextURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+
+		var resp *http.Response
+		if err := retry.Do(ctx, g.retryPolicy, func() error {
+			var doErr error
+			resp, doErr = g.api.Do(req)
+			return doErr
+		}); err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("gitlab api error: %s", resp.Status)
+		}
+
+		var projects []struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
+			return nil, err
+		}
+
+		for _, proj := range projects {
+			for _, pattern := range patterns {
+				matched, err := path.Match(pattern, proj.PathWithNamespace)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+				}
+				if matched {
+					resolvedSet[proj.PathWithNamespace] = struct{}{}
+					break
+				}
+			}
+		}
+
+		nextURL = g.getNextPageURL(resp.Header.Get("Link"))
+	}
+
+	result := make([]string, 0, len(resolvedSet))
+	for repo := range resolvedSet {
+		result = append(result, repo)
+	}
+	return result, nil
+}
+
+func (g *GitLab) GetSecret() string {
+	return g.token
+}
+
+func (g *GitLab) getNextPageURL(linkHeader string) string {
+	if linkHeader == "" {
+		return ""
+	}
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(strings.TrimSpace(link), ";")
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) == `rel="next"` {
+			return strings.Trim(parts[0], "<>")
+		}
+	}
+	return ""
+}
+
+// baseAPIURL resolves gitlab.com vs. a self-hosted instance, appending
+// the v4 API prefix the same way GitHub's driver appends /api/v3.
+func (g *GitLab) baseAPIURL() string {
+	endpoint := strings.TrimSuffix(g.cfg.GetEndpoint(), "/")
+	if endpoint == "" || strings.Contains(endpoint, "gitlab.com") {
+		return "https://gitlab.com/api/v4"
+	}
+	if !strings.Contains(endpoint, "/api/v4") {
+		return endpoint + "/api/v4"
+	}
+	return endpoint
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gateway-service/internal/monitoring"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultSecretCacheTTL is the TTL applied to a CachingSecretManager when
+// its caller doesn't set one explicitly, matching
+// utils.DefaultSecretCacheTTL's value for the other secret-caching path.
+const DefaultSecretCacheTTL = 5 * time.Minute
+
+// cachedSecretValue holds a resolved secret value plus the time it stops
+// being valid. path/key are kept alongside so refreshNearExpiry can
+// re-fetch an entry without parsing it back out of the cache key.
+type cachedSecretValue struct {
+	path, key string
+	value     string
+	expires   time.Time
+}
+
+// CachingSecretManager wraps a SecretManager with an in-process TTL cache
+// in front of GetSecret, so a burst of rule evaluations against the same
+// secret path doesn't each round-trip to Vault/AWS/GCP/file. Concurrent
+// callers racing a cache miss for the same path+key are coalesced via
+// singleflight into a single backend read, and a background loop
+// proactively refreshes entries nearing expiry so GetSecret rarely blocks
+// on a live fetch at all once the cache has warmed up.
+type CachingSecretManager struct {
+	SecretManager
+	ttl time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedSecretValue
+
+	group singleflight.Group
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewCachingSecretManager wraps sm with a TTL cache. ttl <= 0 disables
+// caching entirely — every call passes straight through to sm. refreshAhead
+// > 0 starts a background goroutine that proactively re-fetches any cached
+// entry within refreshAhead of expiring; refreshAhead <= 0 disables
+// background refresh, falling back to the utils.CachingSecretManager
+// behavior of re-fetching lazily on the next GetSecret past expiry.
+func NewCachingSecretManager(sm SecretManager, ttl, refreshAhead time.Duration) *CachingSecretManager {
+	c := &CachingSecretManager{
+		SecretManager: sm,
+		ttl:           ttl,
+		cache:         make(map[string]cachedSecretValue),
+		stopCh:        make(chan struct{}),
+	}
+	if ttl > 0 && refreshAhead > 0 {
+		go c.refreshLoop(refreshAhead)
+	}
+	return c
+}
+
+func secretCacheKey(path, key string) string {
+	return path + "\x00" + key
+}
+
+func (c *CachingSecretManager) GetSecret(ctx context.Context, path string, key string) (string, error) {
+	if c.ttl <= 0 {
+		return c.SecretManager.GetSecret(ctx, path, key)
+	}
+
+	cacheKey := secretCacheKey(path, key)
+
+	c.mu.RLock()
+	entry, ok := c.cache[cacheKey]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		monitoring.Inc("secret_cache_requests_total", "result", "hit")
+		return entry.value, nil
+	}
+
+	monitoring.Inc("secret_cache_requests_total", "result", "miss")
+
+	// singleflight.Group.Do coalesces a thundering herd of callers racing
+	// the same just-expired (or never-cached) path+key into one backend
+	// read; every caller waiting on the in-flight Do gets its result.
+	v, err, _ := c.group.Do(cacheKey, func() (interface{}, error) {
+		return c.fetchAndStore(ctx, path, key, cacheKey)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+func (c *CachingSecretManager) fetchAndStore(ctx context.Context, path, key, cacheKey string) (string, error) {
+	value, err := c.SecretManager.GetSecret(ctx, path, key)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.cache[cacheKey] = cachedSecretValue{
+		path:    path,
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// refreshLoop wakes up every refreshAhead/2 to proactively re-fetch any
+// cached entry within refreshAhead of expiring, so a hot secret's cache
+// stays warm under steady traffic instead of going cold and forcing the
+// next GetSecret to block on a live fetch.
+func (c *CachingSecretManager) refreshLoop(refreshAhead time.Duration) {
+	ticker := time.NewTicker(refreshAhead / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.refreshNearExpiry(refreshAhead)
+		}
+	}
+}
+
+func (c *CachingSecretManager) refreshNearExpiry(refreshAhead time.Duration) {
+	deadline := time.Now().Add(refreshAhead)
+
+	c.mu.RLock()
+	due := make([]cachedSecretValue, 0)
+	for _, entry := range c.cache {
+		if entry.expires.Before(deadline) {
+			due = append(due, entry)
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, entry := range due {
+		cacheKey := secretCacheKey(entry.path, entry.key)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := c.group.Do(cacheKey, func() (interface{}, error) {
+			return c.fetchAndStore(ctx, entry.path, entry.key, cacheKey)
+		})
+		cancel()
+		if err != nil {
+			monitoring.Inc("secret_cache_refresh_failures_total")
+			slog.Warn("secret cache: background refresh failed", "path", entry.path, "error", err)
+		}
+	}
+}
+
+// Purge drops every cached entry, forcing the next GetSecret to hit the
+// wrapped manager. Nothing in this module calls it, so a config reload
+// leaves cached values in place until their ttl expires.
+func (c *CachingSecretManager) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache = make(map[string]cachedSecretValue)
+}
+
+// Close stops the background refresh loop (if running) before delegating
+// to the wrapped SecretManager's own Close.
+func (c *CachingSecretManager) Close(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+	return c.SecretManager.Close(ctx)
+}
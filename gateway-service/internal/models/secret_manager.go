@@ -1,50 +1,28 @@
 package models
 
 import (
-	"context"
-	"fmt"
 	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
 	"gateway-service/internal/utils"
 )
 
-type SecretManager interface {
-	Open(ctx context.Context, config *pb_models.SecretManager) (SecretManager, error)
-	GetSecret(ctx context.Context, path string, key string) (string, error)
-	Close(ctx context.Context) error
-}
-
-var SecretManagerRegistry = utils.NewDriverRegistry[SecretManager, *pb_models.SecretManager](
-	"secret_manager",
-	func(driver SecretManager, ctx context.Context, config *pb_models.SecretManager) (SecretManager, error) {
-		return driver.Open(ctx, config)
-	},
-)
+// SecretManager is an alias for utils.SecretManager rather than a second,
+// independently-declared interface of the same shape. The driver
+// implementations under internal/models/secret_managers (Vault, AWS, GCP,
+// file) register themselves via utils.RegisterSecretManager in their own
+// init() functions, so Bundle needs to resolve them through that exact
+// registry — a lookalike interface here would never be satisfied by any
+// of them.
+type SecretManager = utils.SecretManager
 
-var SecretManagerFactory = utils.NewDriverFactory(
-	SecretManagerRegistry,
-	func(c *pb_models.SecretManager) string {
-		if c == nil {
-			return ""
-		}
-		return GetSecretManagerName(c)
-	},
-	func(c *pb_models.SecretManager) error {
-		if c == nil {
-			return fmt.Errorf("secret manager config cannot be nil")
-		}
-		return nil
-	},
-	"secret_manager",
-)
+// SecretManagerFactory creates secret manager instances from bundle
+// config. It's utils.NewSecretManagerFactory(), not a package-local
+// reimplementation, so it shares the one registry those drivers actually
+// register into.
+var SecretManagerFactory = utils.NewSecretManagerFactory()
 
 // GetSecretManagerName returns the resolved name for a secret manager.
 // If a tag is set, the name is "{type}-{tag}" (e.g. "vault-prod").
 // Otherwise, it falls back to the type name (e.g. "vault").
 func GetSecretManagerName(sm *pb_models.SecretManager) string {
-	switch config := sm.Config.(type) {
-	case *pb_models.SecretManager_Vault:
-		return utils.ResolveTagName("vault", config.Vault.GetTag())
-	default:
-		return ""
-	}
+	return utils.GetSecretManagerName(sm)
 }
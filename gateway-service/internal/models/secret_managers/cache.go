@@ -0,0 +1,246 @@
+package secret_managers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb_vault "gateway-service/internal/gen/proto/go/vartrack/v1/models/secret_managers"
+	"gateway-service/internal/utils"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Default cache parameters for CachingManager, used when a
+// pb_vault.VaultConfig doesn't set the corresponding field (or sets it
+// to <= 0).
+const (
+	DefaultCacheTTL         = 5 * time.Minute
+	DefaultMaxCacheEntries  = 10000
+	DefaultNegativeCacheTTL = 10 * time.Second
+)
+
+// CacheOptions configures CachingManager. See
+// CacheOptionsFromVaultConfig to build one from a pb_vault.VaultConfig.
+type CacheOptions struct {
+	// TTL bounds how long a successful GetSecret result is served from
+	// cache before it must be revalidated (see secretVersioner) or
+	// re-fetched.
+	TTL time.Duration
+
+	// MaxEntries bounds the number of distinct (path,key) entries kept
+	// in memory at once, so an unbounded stream of distinct secret refs
+	// can't grow the cache without limit. Eviction isn't LRU — a cache
+	// at capacity simply drops one arbitrary entry to make room — since
+	// the expected working set is small and stable (the set of secret
+	// refs in the CUE bundle), not something worth a proper LRU for.
+	MaxEntries int
+
+	// NegativeTTL bounds how long a failed GetSecret is cached, so a
+	// Vault outage or a misconfigured ref doesn't get hammered by every
+	// webhook in the meantime while still recovering promptly once
+	// Vault is healthy again.
+	NegativeTTL time.Duration
+}
+
+// CacheOptionsFromVaultConfig builds a CacheOptions from config's
+// cache_ttl_seconds, max_cache_entries and negative_cache_ttl_seconds
+// fields, falling back to the Default* constants for anything unset (or
+// set to <= 0).
+func CacheOptionsFromVaultConfig(config *pb_vault.VaultConfig) CacheOptions {
+	opts := CacheOptions{
+		TTL:         time.Duration(config.GetCacheTtlSeconds()) * time.Second,
+		MaxEntries:  int(config.GetMaxCacheEntries()),
+		NegativeTTL: time.Duration(config.GetNegativeCacheTtlSeconds()) * time.Second,
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCacheTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxCacheEntries
+	}
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = DefaultNegativeCacheTTL
+	}
+	return opts
+}
+
+// secretVersioner is an optional capability a wrapped utils.SecretManager
+// can implement to hand CachingManager a cheap version/ETag for a path —
+// Vault.SecretVersion reads KV v2 metadata instead of the secret value
+// itself. When the wrapped manager doesn't implement it (or reports
+// ok=false, e.g. a KV v1 mount), an expired entry is always treated as a
+// full miss instead of being revalidated.
+type secretVersioner interface {
+	SecretVersion(ctx context.Context, path string) (version string, ok bool, err error)
+}
+
+// cacheEntry holds one (path,key) lookup result. err is non-nil for a
+// negatively-cached failure; version is the secretVersioner ETag the
+// result was fetched at, or "" when the wrapped manager doesn't support
+// revalidation.
+type cacheEntry struct {
+	value   string
+	err     error
+	version string
+	expires time.Time
+}
+
+// CachingManager decorates a utils.SecretManager with an in-process
+// cache in front of GetSecret, purpose-built for Vault: a cache hit
+// within TTL is served with no round trip at all; an entry past TTL is
+// first revalidated via secretVersioner (a cheap KV v2 metadata read)
+// before paying for a full secret fetch, so a rotated SecretRef is
+// picked up on the first read past its TTL while an untouched one keeps
+// serving from cache. Concurrent misses for the same (path,key) are coalesced via
+// singleflight, and a failed lookup is itself cached briefly so a Vault
+// outage doesn't turn every webhook into a retry storm against it.
+type CachingManager struct {
+	utils.SecretManager
+	opts CacheOptions
+
+	sf singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+
+	hits, misses, revalidations atomic.Uint64
+}
+
+// NewCachingManager wraps inner with opts. A zero field in opts falls
+// back to its Default* constant.
+func NewCachingManager(inner utils.SecretManager, opts CacheOptions) *CachingManager {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCacheTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxCacheEntries
+	}
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = DefaultNegativeCacheTTL
+	}
+	return &CachingManager{
+		SecretManager: inner,
+		opts:          opts,
+		entries:       make(map[string]*cacheEntry),
+	}
+}
+
+func (c *CachingManager) GetSecret(ctx context.Context, path string, key string) (string, error) {
+	cacheKey := secretCacheKey(path, key)
+
+	c.mu.Lock()
+	entry, ok := c.entries[cacheKey]
+	c.mu.Unlock()
+
+	if ok {
+		if time.Now().Before(entry.expires) {
+			c.hits.Add(1)
+			slog.Debug("vault secret cache hit", "path", path, "negative", entry.err != nil,
+				"hits", c.hits.Load(), "misses", c.misses.Load())
+			return entry.value, entry.err
+		}
+		if c.revalidate(ctx, path, entry) {
+			c.revalidations.Add(1)
+			slog.Debug("vault secret cache revalidated", "path", path, "version", entry.version,
+				"revalidations", c.revalidations.Load())
+			return entry.value, entry.err
+		}
+	}
+
+	c.misses.Add(1)
+	slog.Debug("vault secret cache miss", "path", path, "hits", c.hits.Load(), "misses", c.misses.Load())
+	return c.fetch(ctx, cacheKey, path, key)
+}
+
+// revalidate checks entry, already past its TTL, against the wrapped
+// manager's current secretVersioner ETag for path. If the ETag is
+// unchanged, entry's expiry is pushed out by another TTL without
+// re-fetching the value, and the cached value is still served. Returns
+// false when the entry has no version (always the case for a negatively
+// cached failure), the wrapped manager doesn't support revalidation, the
+// version changed, or the check itself failed — any of which falls
+// through to a full fetch.
+func (c *CachingManager) revalidate(ctx context.Context, path string, entry *cacheEntry) bool {
+	if entry.version == "" {
+		return false
+	}
+	versioner, ok := c.SecretManager.(secretVersioner)
+	if !ok {
+		return false
+	}
+	version, ok, err := versioner.SecretVersion(ctx, path)
+	if err != nil || !ok || version != entry.version {
+		return false
+	}
+
+	c.mu.Lock()
+	entry.expires = time.Now().Add(c.opts.TTL)
+	c.mu.Unlock()
+	return true
+}
+
+// fetch performs (or joins an already in-flight) backend lookup for
+// cacheKey, storing the result — success or failure — before returning
+// it.
+func (c *CachingManager) fetch(ctx context.Context, cacheKey, path, key string) (string, error) {
+	v, err, _ := c.sf.Do(cacheKey, func() (interface{}, error) {
+		value, fetchErr := c.SecretManager.GetSecret(ctx, path, key)
+
+		var version string
+		if fetchErr == nil {
+			if versioner, ok := c.SecretManager.(secretVersioner); ok {
+				if v, ok, verr := versioner.SecretVersion(ctx, path); verr == nil && ok {
+					version = v
+				}
+			}
+		}
+
+		c.store(cacheKey, value, fetchErr, version)
+		return value, fetchErr
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+func (c *CachingManager) store(cacheKey, value string, err error, version string) {
+	ttl := c.opts.TTL
+	if err != nil {
+		ttl = c.opts.NegativeTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[cacheKey]; !exists && len(c.entries) >= c.opts.MaxEntries {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+
+	c.entries[cacheKey] = &cacheEntry{
+		value:   value,
+		err:     err,
+		version: version,
+		expires: time.Now().Add(ttl),
+	}
+}
+
+// Purge drops every cached entry (positive and negative), forcing the
+// next GetSecret to hit the wrapped manager. Nothing in this module calls
+// it, so a config hot-reload leaves cached values and failures in place
+// until their TTL expires.
+func (c *CachingManager) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*cacheEntry)
+}
+
+func secretCacheKey(path, key string) string {
+	return path + "\x00" + key
+}
@@ -0,0 +1,68 @@
+package secret_managers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	pb_sm "gateway-service/internal/gen/proto/go/vartrack/v1/models/secret_managers"
+	"gateway-service/internal/utils"
+	"os"
+)
+
+var _ utils.SecretManager = (*File)(nil)
+
+func init() {
+	utils.RegisterSecretManager("file", newFile)
+}
+
+// File is a SecretManager driver backed by a single local JSON file,
+// shaped as {"<path>": {"<key>": "<value>", ...}, ...}. It exists so
+// local development and tests can exercise SecretRefResolver without
+// standing up Vault/AWS/GCP.
+type File struct {
+	cfg  *pb_sm.FileSecretManagerConfig
+	data map[string]map[string]string
+}
+
+func newFile() utils.SecretManager {
+	return &File{}
+}
+
+func (f *File) Open(ctx context.Context, config *pb_models.SecretManager) (utils.SecretManager, error) {
+	cfg := config.GetFile()
+	if cfg == nil {
+		return nil, fmt.Errorf("file driver: configuration is missing or not a File type")
+	}
+	f.cfg = cfg
+
+	raw, err := os.ReadFile(cfg.GetPath())
+	if err != nil {
+		return nil, fmt.Errorf("file driver: failed to read secrets file %q: %w", cfg.GetPath(), err)
+	}
+
+	var data map[string]map[string]string
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("file driver: failed to parse secrets file %q: %w", cfg.GetPath(), err)
+	}
+	f.data = data
+
+	return f, nil
+}
+
+func (f *File) GetSecret(ctx context.Context, path string, key string) (string, error) {
+	entry, ok := f.data[path]
+	if !ok {
+		return "", fmt.Errorf("file driver: no secret at path %q", path)
+	}
+
+	value, ok := entry[key]
+	if !ok {
+		return "", fmt.Errorf("file driver: key %q not found in secret %q", key, path)
+	}
+	return value, nil
+}
+
+func (f *File) Close(_ context.Context) error {
+	return nil
+}
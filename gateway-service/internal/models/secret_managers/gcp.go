@@ -0,0 +1,100 @@
+package secret_managers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
+	pb_sm "gateway-service/internal/gen/proto/go/vartrack/v1/models/secret_managers"
+	"gateway-service/internal/utils"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	secretmanagerpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+)
+
+var _ utils.SecretManager = (*GCP)(nil)
+
+func init() {
+	utils.RegisterSecretManager("gcp", newGCP)
+}
+
+// GCP is a SecretManager driver backed by Google Cloud Secret Manager.
+// Paths are fully-qualified resource names
+// ("projects/*/secrets/*/versions/latest") or bare secret IDs, in which
+// case cfg.ProjectId supplies the project segment and "latest" the
+// version. As with the other drivers, key selects a field out of a
+// JSON-shaped secret payload.
+type GCP struct {
+	cfg    *pb_sm.GCPSecretManagerConfig
+	client *secretmanager.Client
+}
+
+func newGCP() utils.SecretManager {
+	return &GCP{}
+}
+
+func (g *GCP) Open(ctx context.Context, config *pb_models.SecretManager) (utils.SecretManager, error) {
+	cfg := config.GetGcp()
+	if cfg == nil {
+		return nil, fmt.Errorf("gcp driver: configuration is missing or not a GCP type")
+	}
+	g.cfg = cfg
+
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcp driver: failed to create client: %w", err)
+	}
+	g.client = client
+	return g, nil
+}
+
+// GetSecret resolves path to a full resource name and, for JSON payloads,
+// extracts the field named by key.
+func (g *GCP) GetSecret(ctx context.Context, path string, key string) (string, error) {
+	name := g.resourceName(path)
+
+	resp, err := g.client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to access %q: %w", name, err)
+	}
+
+	raw := string(resp.GetPayload().GetData())
+	if key == "" {
+		return raw, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return "", fmt.Errorf("gcp secret manager: secret %q is not a JSON object, cannot extract key %q", name, key)
+	}
+
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("gcp secret manager: key %q not found in secret %q", key, name)
+	}
+
+	var value string
+	if err := json.Unmarshal(field, &value); err != nil {
+		return string(field), nil
+	}
+	return value, nil
+}
+
+// resourceName builds "projects/*/secrets/*/versions/latest" from a bare
+// secret ID, or passes a fully-qualified resource name through unchanged.
+func (g *GCP) resourceName(path string) string {
+	if strings.HasPrefix(path, "projects/") {
+		return path
+	}
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/latest", g.cfg.GetProjectId(), path)
+}
+
+func (g *GCP) Close(_ context.Context) error {
+	if g.client != nil {
+		return g.client.Close()
+	}
+	return nil
+}
@@ -8,15 +8,21 @@ import (
 	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
 	pb_vault "gateway-service/internal/gen/proto/go/vartrack/v1/models/secret_managers"
 
+	"gateway-service/internal/monitoring"
 	"gateway-service/internal/utils"
+	"gateway-service/internal/utils/retry"
+	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	vault "github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/api/auth/approle"
+	awsauth "github.com/hashicorp/vault/api/auth/aws"
 	authk8s "github.com/hashicorp/vault/api/auth/kubernetes"
 	authuserpass "github.com/hashicorp/vault/api/auth/userpass"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ utils.SecretManager = (*Vault)(nil)
@@ -28,10 +34,56 @@ func init() {
 type Vault struct {
 	config *pb_vault.VaultConfig
 	client *vault.Client
+
+	// ctx is the context.Context Open was called with. Storing it is an
+	// intentional exception to the usual "don't put contexts in structs"
+	// rule: the background lifetime-watcher goroutine started by
+	// startRenewal needs something to re-authenticate with, and the
+	// caller-supplied Open context (carrying whatever deadline/tracing
+	// the process wiring attaches) is the right one to reuse — not a
+	// detached context.Background() that would outlive an Open the
+	// caller meant to be cancellable.
+	ctx context.Context
+
+	// clientMu guards client's token against concurrent access: GetSecret
+	// (and KV-version detection) take the read lock while the renewal
+	// goroutine's re-authentication path takes the write lock for the
+	// duration of login + client.SetToken, so a hot-path GetSecret never
+	// reads mid-swap.
+	clientMu sync.RWMutex
+
+	// mountVersionsMu guards mountVersions, the per-mount KV version
+	// detected via sys/mounts (see resolveKVVersion). Cached so a hot
+	// secret path doesn't re-probe sys/mounts on every GetSecret call.
+	mountVersionsMu sync.Mutex
+	mountVersions   map[string]int
+
+	// stopCh is closed by Close to stop any in-flight lifetime watcher
+	// goroutine started by startRenewal.
+	stopCh chan struct{}
+
+	// retryPolicy governs authenticate and GetSecret's retry.Do calls.
+	// Defaults to retry.DefaultPolicy() so a briefly unavailable Vault at
+	// pod start doesn't crash the gateway even when nothing overrides it
+	// via SetRetryPolicy.
+	retryPolicy retry.Policy
 }
 
 func newVault() utils.SecretManager {
-	return &Vault{}
+	return &Vault{
+		mountVersions: make(map[string]int),
+		stopCh:        make(chan struct{}),
+		retryPolicy:   retry.DefaultPolicy(),
+	}
+}
+
+// SetRetryPolicy overrides the default retry schedule used for
+// authentication and secret reads. Called by whoever constructs this
+// driver directly (the internal.Router wiring exposes the configured
+// policy via WithRetryPolicy/Router.RetryPolicy for exactly this) since
+// the utils.SecretManager.Open signature has no room for it.
+func (v *Vault) SetRetryPolicy(p retry.Policy) {
+	v.retryPolicy = p
 }
 
 func (v *Vault) Open(ctx context.Context, config *pb_models.SecretManager) (utils.SecretManager, error) {
@@ -41,6 +93,7 @@ func (v *Vault) Open(ctx context.Context, config *pb_models.SecretManager) (util
 	}
 
 	v.config = vaultConfig
+	v.ctx = ctx
 
 	client, err := v.buildClient(ctx)
 	if err != nil {
@@ -118,17 +171,56 @@ func (v *Vault) buildTLSConfig() (*tls.Config, error) {
 // ────────────────────────────────────────────
 
 func (v *Vault) authenticate(ctx context.Context, client *vault.Client) error {
+	ctx, span := monitoring.Start(ctx, "vault.authenticate")
+	defer span.End()
+
+	v.clientMu.Lock()
+	defer v.clientMu.Unlock()
+
+	if auth, ok := v.config.Auth.(*pb_vault.VaultConfig_TokenAuth); ok {
+		err := v.authToken(client, auth.TokenAuth)
+		span.RecordError(err)
+		return err
+	}
+
+	// Retried so a Vault that's briefly unreachable at pod start (still
+	// coming up, or behind a load balancer that hasn't registered this
+	// replica yet) doesn't take the whole gateway down with it.
+	var secret *vault.Secret
+	err := retry.Do(ctx, v.retryPolicy, func() error {
+		var loginErr error
+		secret, loginErr = v.login(ctx, client)
+		return loginErr
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	client.SetToken(secret.Auth.ClientToken)
+	v.startRenewal(client, secret)
+	return nil
+}
+
+// login runs the configured non-token auth method's Login call and returns
+// the resulting auth secret, so authenticate can set the token and hand the
+// lease to startRenewal. Split out from authenticate so re-authentication
+// (triggered by startRenewal when a lifetime watcher gives up) doesn't have
+// to re-derive which branch handles v.config.Auth.
+func (v *Vault) login(ctx context.Context, client *vault.Client) (*vault.Secret, error) {
 	switch auth := v.config.Auth.(type) {
-	case *pb_vault.VaultConfig_TokenAuth:
-		return v.authToken(client, auth.TokenAuth)
 	case *pb_vault.VaultConfig_AppRoleAuth:
 		return v.authAppRole(ctx, client, auth.AppRoleAuth)
 	case *pb_vault.VaultConfig_KubernetesAuth:
 		return v.authKubernetes(ctx, client, auth.KubernetesAuth)
 	case *pb_vault.VaultConfig_UserpassAuth:
 		return v.authUserPass(ctx, client, auth.UserpassAuth)
+	case *pb_vault.VaultConfig_JwtAuth:
+		return v.authJWT(ctx, client, auth.JwtAuth)
+	case *pb_vault.VaultConfig_AwsAuth:
+		return v.authAWS(ctx, client, auth.AwsAuth)
 	default:
-		return fmt.Errorf("unsupported vault auth type: %T", v.config.Auth)
+		return nil, fmt.Errorf("unsupported vault auth type: %T", v.config.Auth)
 	}
 }
 
@@ -140,7 +232,7 @@ func (v *Vault) authToken(client *vault.Client, auth *pb_vault.TokenAuth) error
 	return nil
 }
 
-func (v *Vault) authAppRole(ctx context.Context, client *vault.Client, auth *pb_vault.AppRoleAuth) error {
+func (v *Vault) authAppRole(ctx context.Context, client *vault.Client, auth *pb_vault.AppRoleAuth) (*vault.Secret, error) {
 	secretID := &approle.SecretID{}
 	switch auth.GetSecretIdType() {
 	case pb_vault.AppRoleAuth_PLAIN:
@@ -148,7 +240,7 @@ func (v *Vault) authAppRole(ctx context.Context, client *vault.Client, auth *pb_
 	case pb_vault.AppRoleAuth_ENVIRONMENT:
 		secretID.FromEnv = auth.GetSecretId()
 	default:
-		return fmt.Errorf("unsupported approle secret_id_type: %v", auth.GetSecretIdType())
+		return nil, fmt.Errorf("unsupported approle secret_id_type: %v", auth.GetSecretIdType())
 	}
 
 	var opts []approle.LoginOption
@@ -158,22 +250,21 @@ func (v *Vault) authAppRole(ctx context.Context, client *vault.Client, auth *pb_
 
 	appRoleAuth, err := approle.NewAppRoleAuth(auth.GetRoleId(), secretID, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to create approle auth: %w", err)
+		return nil, fmt.Errorf("failed to create approle auth: %w", err)
 	}
 
 	resp, err := client.Auth().Login(ctx, appRoleAuth)
 	if err != nil {
-		return fmt.Errorf("failed to login with approle: %w", err)
+		return nil, fmt.Errorf("failed to login with approle: %w", err)
 	}
 	if resp == nil || resp.Auth == nil {
-		return fmt.Errorf("vault approle: empty auth response")
+		return nil, fmt.Errorf("vault approle: empty auth response")
 	}
 
-	client.SetToken(resp.Auth.ClientToken)
-	return nil
+	return resp, nil
 }
 
-func (v *Vault) authKubernetes(ctx context.Context, client *vault.Client, auth *pb_vault.KubernetesAuth) error {
+func (v *Vault) authKubernetes(ctx context.Context, client *vault.Client, auth *pb_vault.KubernetesAuth) (*vault.Secret, error) {
 	jwtPath := auth.GetJwtPath()
 	if jwtPath == "" {
 		jwtPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
@@ -181,7 +272,7 @@ func (v *Vault) authKubernetes(ctx context.Context, client *vault.Client, auth *
 
 	jwt, err := os.ReadFile(jwtPath)
 	if err != nil {
-		return fmt.Errorf("failed to read kubernetes JWT from %s: %w", jwtPath, err)
+		return nil, fmt.Errorf("failed to read kubernetes JWT from %s: %w", jwtPath, err)
 	}
 
 	opts := []authk8s.LoginOption{
@@ -193,22 +284,21 @@ func (v *Vault) authKubernetes(ctx context.Context, client *vault.Client, auth *
 
 	k8sAuth, err := authk8s.NewKubernetesAuth(auth.GetRole(), opts...)
 	if err != nil {
-		return fmt.Errorf("failed to create kubernetes auth: %w", err)
+		return nil, fmt.Errorf("failed to create kubernetes auth: %w", err)
 	}
 
 	resp, err := client.Auth().Login(ctx, k8sAuth)
 	if err != nil {
-		return fmt.Errorf("failed to login with kubernetes: %w", err)
+		return nil, fmt.Errorf("failed to login with kubernetes: %w", err)
 	}
 	if resp == nil || resp.Auth == nil {
-		return fmt.Errorf("vault kubernetes: empty auth response")
+		return nil, fmt.Errorf("vault kubernetes: empty auth response")
 	}
 
-	client.SetToken(resp.Auth.ClientToken)
-	return nil
+	return resp, nil
 }
 
-func (v *Vault) authUserPass(ctx context.Context, client *vault.Client, auth *pb_vault.UserPassAuth) error {
+func (v *Vault) authUserPass(ctx context.Context, client *vault.Client, auth *pb_vault.UserPassAuth) (*vault.Secret, error) {
 	var opts []authuserpass.LoginOption
 	if mp := auth.GetMountPath(); mp != "" {
 		opts = append(opts, authuserpass.WithMountPath(mp))
@@ -220,19 +310,132 @@ func (v *Vault) authUserPass(ctx context.Context, client *vault.Client, auth *pb
 		opts...,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create userpass auth: %w", err)
+		return nil, fmt.Errorf("failed to create userpass auth: %w", err)
 	}
 
 	resp, err := client.Auth().Login(ctx, userpassAuth)
 	if err != nil {
-		return fmt.Errorf("failed to login with userpass: %w", err)
+		return nil, fmt.Errorf("failed to login with userpass: %w", err)
 	}
 	if resp == nil || resp.Auth == nil {
-		return fmt.Errorf("vault userpass: empty auth response")
+		return nil, fmt.Errorf("vault userpass: empty auth response")
 	}
 
-	client.SetToken(resp.Auth.ClientToken)
-	return nil
+	return resp, nil
+}
+
+// authJWT implements the JWT/OIDC auth method's role-bound JWT login (the
+// non-interactive half of the method — OIDC's browser-driven login flow
+// has no equivalent for a headless gateway process). There's no official
+// vault/api/auth/jwt login helper, so this writes the login request
+// directly, the same "role" + "jwt" shape the auth method's own HTTP API
+// documents.
+func (v *Vault) authJWT(ctx context.Context, client *vault.Client, auth *pb_vault.JWTAuth) (*vault.Secret, error) {
+	if auth.GetJwt() == "" {
+		return nil, fmt.Errorf("vault jwt auth: jwt is empty")
+	}
+
+	mountPath := auth.GetMountPath()
+	if mountPath == "" {
+		mountPath = "jwt"
+	}
+
+	data := map[string]interface{}{
+		"role": auth.GetRole(),
+		"jwt":  auth.GetJwt(),
+	}
+
+	resp, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mountPath), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to login with jwt: %w", err)
+	}
+	if resp == nil || resp.Auth == nil {
+		return nil, fmt.Errorf("vault jwt: empty auth response")
+	}
+
+	return resp, nil
+}
+
+// authAWS implements the AWS IAM auth method via the official client
+// helper, which signs and submits the sts:GetCallerIdentity request that
+// actually proves the instance/task/role identity — hand-rolling that
+// signing here would just duplicate awsauth.NewAWSAuth.
+func (v *Vault) authAWS(ctx context.Context, client *vault.Client, auth *pb_vault.AWSAuth) (*vault.Secret, error) {
+	var opts []awsauth.LoginOption
+	if r := auth.GetRole(); r != "" {
+		opts = append(opts, awsauth.WithRole(r))
+	}
+	if mp := auth.GetMountPath(); mp != "" {
+		opts = append(opts, awsauth.WithMountPath(mp))
+	}
+
+	awsAuthMethod, err := awsauth.NewAWSAuth(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aws auth: %w", err)
+	}
+
+	resp, err := client.Auth().Login(ctx, awsAuthMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to login with aws: %w", err)
+	}
+	if resp == nil || resp.Auth == nil {
+		return nil, fmt.Errorf("vault aws: empty auth response")
+	}
+
+	return resp, nil
+}
+
+// startRenewal keeps secret's lease alive for as long as this process runs,
+// using vault.NewLifetimeWatcher the same way Vault's own documentation
+// recommends for long-lived clients. Every successful renewal on RenewCh
+// is logged so lease activity shows up in the same structured log stream
+// as everything else. If the watcher gives up — the lease expired, got
+// revoked, or hit a renewal error it can't recover from — DoneCh fires,
+// and startRenewal re-runs the original login from scratch (via v.ctx,
+// the context Open was called with) and starts a fresh watcher for the
+// new lease, so the gateway never has to be restarted to pick up a
+// rotated Kubernetes SA JWT or refreshed AppRole SecretID.
+func (v *Vault) startRenewal(client *vault.Client, secret *vault.Secret) {
+	if secret == nil || secret.Auth == nil || !secret.Auth.Renewable {
+		return
+	}
+
+	watcher, err := client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: secret})
+	if err != nil {
+		slog.Error("vault: failed to start lifetime watcher", "error", err)
+		return
+	}
+
+	go watcher.Start()
+	go func() {
+		defer watcher.Stop()
+		for {
+			select {
+			case <-v.stopCh:
+				return
+
+			case renewal, ok := <-watcher.RenewCh():
+				if !ok {
+					continue
+				}
+				slog.Info("vault: lease renewed", "lease_duration", renewal.Secret.LeaseDuration)
+
+			case werr, ok := <-watcher.DoneCh():
+				if !ok {
+					return
+				}
+				if werr != nil {
+					slog.Warn("vault: lifetime watcher stopped, re-authenticating", "error", werr)
+				} else {
+					slog.Info("vault: lease expired, re-authenticating")
+				}
+				if err := v.authenticate(v.ctx, client); err != nil {
+					slog.Error("vault: re-authentication failed", "error", err)
+				}
+				return
+			}
+		}
+	}()
 }
 
 // ────────────────────────────────────────────
@@ -240,50 +443,168 @@ func (v *Vault) authUserPass(ctx context.Context, client *vault.Client, auth *pb
 // ────────────────────────────────────────────
 
 func (v *Vault) GetSecret(ctx context.Context, path string, key string) (string, error) {
+	ctx, span := monitoring.Start(ctx, "vault.get_secret")
+	defer span.End()
+	span.SetAttributes(attribute.String("vault.path", path))
+
+	v.clientMu.RLock()
+	defer v.clientMu.RUnlock()
+
 	mountPoint := v.config.GetMountPoint()
 
-	var data map[string]interface{}
+	kvVersion, err := v.resolveKVVersion(ctx, mountPoint)
+	if err != nil {
+		err = fmt.Errorf("failed to resolve KV version for mount %q: %w", mountPoint, err)
+		span.RecordError(err)
+		return "", err
+	}
+	span.SetAttributes(attribute.Int("vault.kv_version", kvVersion))
 
-	switch v.config.GetKvVersion() {
-	case 1:
-		secret, err := v.client.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/%s", mountPoint, path))
-		if err != nil {
-			return "", fmt.Errorf("failed to read KV v1 secret at %q: %w", path, err)
-		}
-		if secret == nil || secret.Data == nil {
-			return "", fmt.Errorf("secret not found at %q", path)
-		}
-		data = secret.Data
+	var data map[string]interface{}
 
-	case 2:
-		secret, err := v.client.KVv2(mountPoint).Get(ctx, path)
-		if err != nil {
-			return "", fmt.Errorf("failed to read KV v2 secret at %q: %w", path, err)
-		}
-		if secret == nil || secret.Data == nil {
-			return "", fmt.Errorf("secret not found at %q", path)
+	// Wrapped in retry.Do rather than relying solely on the vault
+	// client's own MaxRetries, so a 429/503 surfaced above the HTTP
+	// transport (e.g. from a proxy in front of Vault) still gets retried
+	// with the same backoff schedule used for authenticate.
+	err = retry.Do(ctx, v.retryPolicy, func() error {
+		switch kvVersion {
+		case 1:
+			secret, err := v.client.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/%s", mountPoint, path))
+			if err != nil {
+				return fmt.Errorf("failed to read KV v1 secret at %q: %w", path, err)
+			}
+			if secret == nil || secret.Data == nil {
+				return fmt.Errorf("secret not found at %q", path)
+			}
+			data = secret.Data
+
+		case 2:
+			secret, err := v.client.KVv2(mountPoint).Get(ctx, path)
+			if err != nil {
+				return fmt.Errorf("failed to read KV v2 secret at %q: %w", path, err)
+			}
+			if secret == nil || secret.Data == nil {
+				return fmt.Errorf("secret not found at %q", path)
+			}
+			data = secret.Data
+
+		default:
+			return fmt.Errorf("unsupported KV version: %d", kvVersion)
 		}
-		data = secret.Data
-
-	default:
-		return "", fmt.Errorf("unsupported KV version: %d", v.config.GetKvVersion())
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return "", err
 	}
 
 	value, ok := data[key].(string)
 	if !ok {
-		return "", fmt.Errorf("key %q not found or not a string in secret %q", key, path)
+		err := fmt.Errorf("key %q not found or not a string in secret %q", key, path)
+		span.RecordError(err)
+		return "", err
 	}
 
 	return value, nil
 }
 
+// SecretVersion reports a cheap version/ETag for path, for use by
+// CachingManager (see cache.go) to revalidate an expired cache entry
+// without re-fetching and re-decrypting the secret value itself. It
+// reads only KV v2 metadata — current version plus its last-updated
+// time — so it's a much lighter round trip than GetSecret. ok is false
+// for a KV v1 mount, which has no version metadata to read; the caller
+// falls back to plain TTL expiry in that case.
+func (v *Vault) SecretVersion(ctx context.Context, path string) (version string, ok bool, err error) {
+	v.clientMu.RLock()
+	defer v.clientMu.RUnlock()
+
+	mountPoint := v.config.GetMountPoint()
+
+	kvVersion, err := v.resolveKVVersion(ctx, mountPoint)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to resolve KV version for mount %q: %w", mountPoint, err)
+	}
+	if kvVersion != 2 {
+		return "", false, nil
+	}
+
+	meta, err := v.client.KVv2(mountPoint).GetMetadata(ctx, path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read KV v2 metadata at %q: %w", path, err)
+	}
+
+	return fmt.Sprintf("%d@%s", meta.CurrentVersion, meta.UpdatedTime.UTC().Format(time.RFC3339Nano)), true, nil
+}
+
+// ────────────────────────────────────────────
+// KV version detection
+// ────────────────────────────────────────────
+
+// resolveKVVersion decides which KV read path GetSecret should use for
+// mountPoint, in order of precedence:
+//  1. ForceKvVersion — for air-gapped setups where sys/mounts isn't
+//     reachable (e.g. a scoped-down token), operators pin the version
+//     explicitly and detectKVVersion is never called.
+//  2. KvVersion — the pre-existing explicit config knob.
+//  3. Auto-detection via detectKVVersion, cached per mount so rotated
+//     secrets (which only change the leaf value, never the mount's KV
+//     version) don't pay a sys/mounts round-trip on every read.
+func (v *Vault) resolveKVVersion(ctx context.Context, mountPoint string) (int, error) {
+	if fv := v.config.GetForceKvVersion(); fv == 1 || fv == 2 {
+		return int(fv), nil
+	}
+	if kv := v.config.GetKvVersion(); kv == 1 || kv == 2 {
+		return int(kv), nil
+	}
+	return v.detectKVVersion(ctx, mountPoint)
+}
+
+// detectKVVersion probes sys/mounts for mountPoint's "version" option,
+// caching the result so repeated GetSecret calls against the same mount
+// don't re-probe. A mount with no "version" option (or a KV v1 mount,
+// which doesn't set one) is treated as version 1.
+func (v *Vault) detectKVVersion(ctx context.Context, mountPoint string) (int, error) {
+	v.mountVersionsMu.Lock()
+	if version, ok := v.mountVersions[mountPoint]; ok {
+		v.mountVersionsMu.Unlock()
+		return version, nil
+	}
+	v.mountVersionsMu.Unlock()
+
+	mounts, err := v.client.Sys().ListMountsWithContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sys/mounts: %w", err)
+	}
+
+	mount, ok := mounts[mountPoint+"/"]
+	if !ok {
+		return 0, fmt.Errorf("mount %q not found in sys/mounts", mountPoint)
+	}
+
+	version := 1
+	if mount.Options["version"] == "2" {
+		version = 2
+	}
+
+	v.mountVersionsMu.Lock()
+	v.mountVersions[mountPoint] = version
+	v.mountVersionsMu.Unlock()
+
+	return version, nil
+}
+
 // ────────────────────────────────────────────
 // Lifecycle
 // ────────────────────────────────────────────
 
 func (v *Vault) Close(_ context.Context) error {
+	close(v.stopCh)
+
+	v.clientMu.Lock()
+	defer v.clientMu.Unlock()
 	if v.client != nil {
 		v.client.ClearToken()
 	}
 	return nil
-}
\ No newline at end of file
+}
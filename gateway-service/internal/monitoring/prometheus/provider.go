@@ -0,0 +1,222 @@
+// Package prometheus implements monitoring.MetricProvider on top of
+// github.com/prometheus/client_golang, the concrete backend the abstract
+// interface in internal/monitoring has been waiting on since it shipped
+// with only a nopProvider.
+package prometheus
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricSpec describes a metric's static properties, pre-declared so a
+// caller's free-form monitoring.Inc/Observe/Set label maps can be
+// validated instead of flowing straight into a CounterVec unchecked.
+type MetricSpec struct {
+	// Help is the metric's HELP text. Falls back to a generic string
+	// naming the metric when empty, since client_golang requires one.
+	Help string
+
+	// Buckets configures a histogram's bucket boundaries. Ignored for
+	// counters and gauges. Defaults to prometheus.DefBuckets when unset.
+	Buckets []float64
+
+	// AllowedLabels, when non-empty, is the only set of label keys this
+	// metric accepts — a call with a key outside it has that key silently
+	// dropped rather than handed to a *Vec, which would otherwise grow a
+	// new time series per unexpected value (or, if a caller starts
+	// varying an unforeseen key, per typo).
+	AllowedLabels []string
+}
+
+// Config configures a Provider.
+type Config struct {
+	// Metrics maps a metric name to its MetricSpec. A name with no entry
+	// gets a generic help string, prometheus.DefBuckets, and no label
+	// allowlist.
+	Metrics map[string]MetricSpec
+}
+
+// Provider implements monitoring.MetricProvider. Inc/Observe/Set accept
+// arbitrary map[string]string labels, but a *prometheus.CounterVec (etc.)
+// is fixed to one label key set for its lifetime, so collectors are built
+// lazily and cached per (metric name, sorted label key set) — the first
+// call for a given pair registers the vec with reg; later calls with the
+// same key set reuse it, and a call with the same name but a different
+// key set gets its own, independently-registered vec.
+type Provider struct {
+	reg prometheus.Registerer
+	cfg Config
+
+	counters   sync.Map // vecKey -> *prometheus.CounterVec
+	histograms sync.Map // vecKey -> *prometheus.HistogramVec
+	gauges     sync.Map // vecKey -> *prometheus.GaugeVec
+
+	gatherer prometheus.Gatherer // for Handler; nil unless reg is also a Gatherer
+}
+
+// New creates a Provider that registers collectors into reg as they're
+// first used. Passing a *prometheus.Registry (rather than the global
+// prometheus.DefaultRegisterer) lets a caller scrape exactly what this
+// Provider produced via Handler, the same way internal/metrics.Registry
+// is scraped by AdminServer.
+func New(reg *prometheus.Registry, cfg Config) *Provider {
+	return &Provider{reg: reg, cfg: cfg, gatherer: reg}
+}
+
+// Handler returns an http.Handler serving everything registered through
+// this Provider, for mounting next to (or instead of) AdminServer's
+// existing /metrics wiring.
+func (p *Provider) Handler() http.Handler {
+	return promhttp.HandlerFor(p.gatherer, promhttp.HandlerOpts{})
+}
+
+// Inc implements monitoring.MetricProvider.
+func (p *Provider) Inc(name string, labels map[string]string) {
+	p.safeCall(name, func() {
+		keys, values := p.labelKV(name, labels)
+		p.counterVec(name, keys).WithLabelValues(values...).Inc()
+	})
+}
+
+// Observe implements monitoring.MetricProvider.
+func (p *Provider) Observe(name string, value float64, labels map[string]string) {
+	p.safeCall(name, func() {
+		keys, values := p.labelKV(name, labels)
+		p.histogramVec(name, keys).WithLabelValues(values...).Observe(value)
+	})
+}
+
+// Set implements monitoring.MetricProvider.
+func (p *Provider) Set(name string, value float64, labels map[string]string) {
+	p.safeCall(name, func() {
+		keys, values := p.labelKV(name, labels)
+		p.gaugeVec(name, keys).WithLabelValues(values...).Set(value)
+	})
+}
+
+// safeCall recovers a panic from client_golang — most commonly
+// "inconsistent label cardinality" from WithLabelValues, or a duplicate
+// registration with a mismatched label set — and logs it instead of
+// letting it crash whatever webhook/RPC handler happened to be recording
+// a metric at the time.
+func (p *Provider) safeCall(name string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("monitoring/prometheus: recovered from client_golang panic",
+				"metric", name, "panic", fmt.Sprint(r))
+		}
+	}()
+	fn()
+}
+
+// labelKV filters labels down to name's AllowedLabels (if configured) and
+// returns its keys and values as parallel, key-sorted slices — the shape
+// *Vec.WithLabelValues expects, and stable regardless of map iteration
+// order.
+func (p *Provider) labelKV(name string, labels map[string]string) ([]string, []string) {
+	allowed := p.cfg.Metrics[name].AllowedLabels
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if len(allowed) > 0 && !contains(allowed, k) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = labels[k]
+	}
+	return keys, values
+}
+
+func (p *Provider) counterVec(name string, keys []string) *prometheus.CounterVec {
+	key := vecKey(name, keys)
+	if v, ok := p.counters.Load(key); ok {
+		return v.(*prometheus.CounterVec)
+	}
+	spec := p.cfg.Metrics[name]
+	cv := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: name,
+		Help: helpOrDefault(spec.Help, name),
+	}, keys)
+
+	actual, loaded := p.counters.LoadOrStore(key, cv)
+	if !loaded {
+		p.reg.MustRegister(cv)
+	}
+	return actual.(*prometheus.CounterVec)
+}
+
+func (p *Provider) histogramVec(name string, keys []string) *prometheus.HistogramVec {
+	key := vecKey(name, keys)
+	if v, ok := p.histograms.Load(key); ok {
+		return v.(*prometheus.HistogramVec)
+	}
+	spec := p.cfg.Metrics[name]
+	buckets := spec.Buckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	hv := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    helpOrDefault(spec.Help, name),
+		Buckets: buckets,
+	}, keys)
+
+	actual, loaded := p.histograms.LoadOrStore(key, hv)
+	if !loaded {
+		p.reg.MustRegister(hv)
+	}
+	return actual.(*prometheus.HistogramVec)
+}
+
+func (p *Provider) gaugeVec(name string, keys []string) *prometheus.GaugeVec {
+	key := vecKey(name, keys)
+	if v, ok := p.gauges.Load(key); ok {
+		return v.(*prometheus.GaugeVec)
+	}
+	spec := p.cfg.Metrics[name]
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: name,
+		Help: helpOrDefault(spec.Help, name),
+	}, keys)
+
+	actual, loaded := p.gauges.LoadOrStore(key, gv)
+	if !loaded {
+		p.reg.MustRegister(gv)
+	}
+	return actual.(*prometheus.GaugeVec)
+}
+
+// vecKey identifies a lazily-created *Vec by metric name and its sorted
+// label key set — the same name called with two different label key sets
+// is, as far as client_golang is concerned, two different collectors.
+func vecKey(name string, sortedKeys []string) string {
+	return name + "|" + strings.Join(sortedKeys, ",")
+}
+
+func helpOrDefault(help, name string) string {
+	if help != "" {
+		return help
+	}
+	return name + " (no help text configured)"
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
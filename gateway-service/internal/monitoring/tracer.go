@@ -3,29 +3,192 @@ package monitoring
 import (
 	"context"
 	"fmt"
-	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-// Span represents a single operation
+// tracerName is the instrumentation scope name passed to otel.Tracer. A
+// single fixed name is fine here since this package is the gateway's only
+// tracer — services with multiple distinct subsystems would scope one per
+// subsystem instead.
+const tracerName = "gateway-service"
+
+// TracingConfig configures Init's OTLP exporter and resource attributes.
+type TracingConfig struct {
+	// ServiceName and ServiceVersion populate the resource's
+	// service.name/service.version attributes, so every span this gateway
+	// emits is attributable in a shared backend (Jaeger/Tempo/etc).
+	ServiceName    string
+	ServiceVersion string
+
+	// OTLPEndpoint is the collector's host:port (no scheme), e.g.
+	// "otel-collector:4317" for gRPC or "otel-collector:4318" for HTTP.
+	OTLPEndpoint string
+
+	// OTLPProtocol selects the exporter transport: "grpc" (default) or
+	// "http". Matches the two transports the OTel collector accepts on
+	// its standard ports.
+	OTLPProtocol string
+
+	// Insecure disables TLS on the exporter connection, for talking to a
+	// collector sidecar over a trusted local/cluster network.
+	Insecure bool
+}
+
+// DefaultTracingConfig returns the config Init falls back to for any field
+// left zero-valued by the caller.
+func DefaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		ServiceName:    tracerName,
+		ServiceVersion: "dev",
+		OTLPProtocol:   "grpc",
+		Insecure:       true,
+	}
+}
+
+// Init configures the global OpenTelemetry TracerProvider backed by an OTLP
+// exporter and registers the W3C tracecontext+baggage propagator as the
+// global propagator, so Start and middlewares.CorrelationID immediately
+// pick up real spans instead of the no-op ones OTel hands out by default.
+//
+// It returns a shutdown func that flushes buffered spans and closes the
+// exporter; callers should defer it with a bounded context, the same way
+// AdminServer.Shutdown and the gRPC conn.Close() are deferred in
+// cmd/main.go's startup sequence.
+//
+// Init is optional: a gateway that never calls it still works, it just
+// traces with OTel's default no-op provider, mirroring how this package's
+// metrics side is a no-op until monitoring.RegisterProvider is called.
+func Init(ctx context.Context, cfg TracingConfig) (shutdown func(context.Context) error, err error) {
+	def := DefaultTracingConfig()
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = def.ServiceName
+	}
+	if cfg.ServiceVersion == "" {
+		cfg.ServiceVersion = def.ServiceVersion
+	}
+	if cfg.OTLPProtocol == "" {
+		cfg.OTLPProtocol = def.OTLPProtocol
+	}
+
+	exp, err := newOTLPExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("monitoring: failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("monitoring: failed to build resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+func newOTLPExporter(ctx context.Context, cfg TracingConfig) (sdktrace.SpanExporter, error) {
+	if cfg.OTLPProtocol == "http" {
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// Span represents a single traced operation, wrapping an OTel span so
+// callers outside this package never need to import
+// go.opentelemetry.io/otel/trace directly — the same boundary
+// MetricProvider draws around the Prometheus client.
 type Span interface {
+	// SetAttributes attaches key/value metadata to the span, e.g. the
+	// gateway's correlation ID alongside the trace ID OTel already tracks.
+	SetAttributes(attrs ...attribute.KeyValue)
+
+	// RecordError records err as a span event and marks the span's status
+	// as an error, so a trace backend can filter/alert on failed spans
+	// without parsing log lines.
+	RecordError(err error)
+
+	// End completes the span. If RecordError was never called, the span's
+	// status is left at its default (Unset), which OTel UIs render as ok.
 	End()
 }
 
-// SimpleSpan just logs when an operation finished
-type SimpleSpan struct {
-	Name      string
-	StartTime time.Time
+type otelSpan struct {
+	span oteltrace.Span
 }
 
-func (s *SimpleSpan) End() {
-	fmt.Printf("[TRACE] Finished: %s | Duration: %v\n", s.Name, time.Since(s.StartTime))
+func (s *otelSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	s.span.SetAttributes(attrs...)
 }
 
-// Start creates a new span. In a real OTel setup, this would inject IDs into the context.
+func (s *otelSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+// Start starts a new span named name as a child of any span already in ctx
+// (e.g. one extracted from an incoming traceparent header by
+// middlewares.CorrelationID), using the global TracerProvider Init
+// installs. Without Init, this returns a no-op span via OTel's default
+// global provider — callers don't need to branch on whether tracing is
+// configured.
 func Start(ctx context.Context, name string) (context.Context, Span) {
-	fmt.Printf("[TRACE] Starting: %s\n", name)
-	return ctx, &SimpleSpan{
-		Name:      name,
-		StartTime: time.Now(),
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name)
+	return ctx, &otelSpan{span: span}
+}
+
+// TraceIDFromContext returns the hex-encoded trace ID of the span carried
+// by ctx, or "" if ctx carries no valid span context (tracing disabled, or
+// called outside a Start'd span).
+func TraceIDFromContext(ctx context.Context) string {
+	sc := oteltrace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
 	}
+	return sc.TraceID().String()
+}
+
+// Propagator returns the global OTel text-map propagator: the W3C
+// tracecontext+baggage propagator once Init has run, otherwise OTel's
+// default no-op propagator. middlewares.CorrelationID uses this to extract
+// an incoming traceparent/tracestate pair without importing
+// go.opentelemetry.io/otel directly.
+func Propagator() propagation.TextMapPropagator {
+	return otel.GetTextMapPropagator()
 }
@@ -3,21 +3,34 @@ package internal
 import (
 	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
 	"gateway-service/internal/handlers"
+	"gateway-service/internal/metrics"
 	"gateway-service/internal/middlewares"
 	"gateway-service/internal/models"
 	"gateway-service/internal/routes"
+	"gateway-service/internal/utils/retry"
 	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Router struct {
-	mux           *http.ServeMux
-	bundleService *models.Bundle
-	grpcClient    pb.OrchestratorClient
-	grpcConn      handlers.GRPCConnChecker
-	limiter       *middlewares.RateLimiter
-	breaker       *middlewares.CircuitBreaker
-	healthHandler *handlers.HealthHandler
-	handler       http.Handler // final handler chain with middleware
+	mux            *http.ServeMux
+	bundleService  *models.Bundle
+	grpcClient     pb.OrchestratorClient
+	grpcConn       handlers.GRPCConnChecker
+	limiter        *middlewares.RateLimiter
+	breaker        *middlewares.CircuitBreaker
+	queue          *handlers.WebhookQueue
+	queueCfg       handlers.WebhookQueueConfig
+	idempotency    handlers.IdempotencyStore
+	idempotencyTTL time.Duration
+	healthHandler  *handlers.HealthHandler
+	retryPolicy    retry.Policy
+	tracerProvider trace.TracerProvider
+	handler        http.Handler // final handler chain with middleware
 }
 
 // RouterOption configures optional Router behaviour.
@@ -33,12 +46,62 @@ func WithRateLimiterConfig(cfg middlewares.RateLimiterConfig) RouterOption {
 }
 
 // WithCircuitBreakerConfig overrides the default circuit breaker settings.
+// If cfg.OnStateChange is nil, state changes still feed
+// metrics.RecordCircuitBreakerStateChange — operators tuning thresholds
+// don't also have to remember to re-wire the metric.
 func WithCircuitBreakerConfig(cfg middlewares.CircuitBreakerConfig) RouterOption {
 	return func(r *Router) {
+		if cfg.OnStateChange == nil {
+			cfg.OnStateChange = metrics.RecordCircuitBreakerStateChange
+		}
 		r.breaker = middlewares.NewCircuitBreaker(cfg)
 	}
 }
 
+// WithWebhookQueueConfig overrides the default async webhook queue
+// settings. Applied after the circuit breaker (default or overridden) is
+// in place, since WebhookQueue's workers report delivery outcomes onto it.
+func WithWebhookQueueConfig(cfg handlers.WebhookQueueConfig) RouterOption {
+	return func(r *Router) {
+		r.queueCfg = cfg
+	}
+}
+
+// WithRetryPolicy overrides the default exponential-backoff retry policy
+// used for Vault and Platform driver I/O (see utils/retry). The Vault and
+// Platform drivers themselves are constructed elsewhere, during config/
+// bundle loading rather than by Router — callers that build those
+// drivers directly should pull the configured policy back out via
+// Router.RetryPolicy (e.g. secret_managers.Vault.SetRetryPolicy) so one
+// flag tunes both.
+func WithRetryPolicy(policy retry.Policy) RouterOption {
+	return func(r *Router) {
+		r.retryPolicy = policy
+	}
+}
+
+// WithTracing overrides the TracerProvider the outermost otelhttp
+// middleware (see buildMiddlewareChain) uses to start each request's root
+// span. Defaults to otel.GetTracerProvider(), i.e. whatever
+// monitoring.Init installed globally (a no-op provider if it was never
+// called) — tests inject a dedicated no-op or in-memory provider here
+// instead of touching the global one.
+func WithTracing(tp trace.TracerProvider) RouterOption {
+	return func(r *Router) {
+		r.tracerProvider = tp
+	}
+}
+
+// WithIdempotencyStore overrides the default in-memory duplicate-delivery
+// store (e.g. with a handlers.RedisIdempotencyStore shared across
+// replicas). ttl <= 0 keeps handlers.DefaultIdempotencyTTL.
+func WithIdempotencyStore(store handlers.IdempotencyStore, ttl time.Duration) RouterOption {
+	return func(r *Router) {
+		r.idempotency = store
+		r.idempotencyTTL = ttl
+	}
+}
+
 func NewRouter(
 	bundleService *models.Bundle,
 	grpcClient pb.OrchestratorClient,
@@ -61,7 +124,22 @@ func NewRouter(
 		r.limiter = middlewares.NewRateLimiter(middlewares.DefaultRateLimiterConfig())
 	}
 	if r.breaker == nil {
-		r.breaker = middlewares.NewCircuitBreaker(middlewares.DefaultCircuitBreakerConfig())
+		cfg := middlewares.DefaultCircuitBreakerConfig()
+		cfg.OnStateChange = metrics.RecordCircuitBreakerStateChange
+		r.breaker = middlewares.NewCircuitBreaker(cfg)
+	}
+	if r.queueCfg.Workers == 0 {
+		r.queueCfg = handlers.DefaultWebhookQueueConfig()
+	}
+	r.queue = handlers.NewWebhookQueue(r.grpcClient, r.breaker, r.queueCfg)
+	if r.idempotency == nil {
+		r.idempotency = handlers.NewMemoryIdempotencyStore(0)
+	}
+	if r.retryPolicy.InitialInterval == 0 {
+		r.retryPolicy = retry.DefaultPolicy()
+	}
+	if r.tracerProvider == nil {
+		r.tracerProvider = otel.GetTracerProvider()
 	}
 
 	r.setupRoutes()
@@ -76,6 +154,19 @@ func (r *Router) HealthHandler() *handlers.HealthHandler {
 	return r.healthHandler
 }
 
+// CircuitBreaker returns the shared circuit breaker so the admin server
+// can expose its per-key state at /debug/breakers.
+func (r *Router) CircuitBreaker() *middlewares.CircuitBreaker {
+	return r.breaker
+}
+
+// RetryPolicy returns the configured Vault/Platform retry policy (see
+// WithRetryPolicy) so code that constructs those drivers outside the
+// Router's own wiring can apply the same schedule.
+func (r *Router) RetryPolicy() retry.Policy {
+	return r.retryPolicy
+}
+
 // SetUnavailable marks the server as shutting down so readiness probes
 // return 503, allowing the load balancer to drain traffic. Mirrors
 // ArgoCD's shutdownFunc: server.available.Store(false).
@@ -83,6 +174,15 @@ func (r *Router) SetUnavailable() {
 	r.healthHandler.SetUnavailable()
 }
 
+// Close drains the webhook queue's buffered and in-flight deliveries
+// (bounded by its configured DrainTimeout) so a shutdown never silently
+// discards a webhook that was already accepted with a 202. Call this
+// after internal.Run has returned (no new requests can be accepted) and
+// before closing the gRPC connection the queue was built from.
+func (r *Router) Close() {
+	r.queue.Close()
+}
+
 func (r *Router) setupRoutes() {
 	// Health routes on the public mux — kept for backward compatibility.
 	// The admin server also exposes these on a separate port.
@@ -97,13 +197,20 @@ func (r *Router) setupRoutes() {
 	// Rate limiting applied per-route group so health probes are never
 	// throttled. Circuit breaker is injected into the handler itself.
 	r.mux.Handle("/webhooks/", http.StripPrefix("/webhooks",
-		r.limiter.Middleware(routes.WebhookRoutes(r.bundleService, r.grpcClient, r.breaker)),
+		r.limiter.Middleware(routes.WebhookRoutes(r.bundleService, r.queue, r.breaker, r.limiter, r.idempotency, r.idempotencyTTL)),
 	))
 }
 
 func (r *Router) buildMiddlewareChain() {
 	// Outermost → innermost:
-	//   Recovery → SecurityHeaders → RequestLog → RequestID → CorrelationID → mux
+	//   otelhttp → Recovery → SecurityHeaders → RequestLog → RequestID → Metrics → CorrelationID → mux
+	//
+	// otelhttp sits outside everything else so its root span covers the
+	// full request lifecycle, including Recovery's panic handling — by
+	// the time RequestID and CorrelationID run, oteltrace.SpanFromContext
+	// already resolves to this span, so their IDs land as its attributes
+	// instead of starting a detached one. CorrelationID's own child span
+	// (see middlewares.CorrelationID) nests underneath it.
 	//
 	// This mirrors Bytebase's configureEchoRouters ordering:
 	//   recoverMiddleware → securityHeadersMiddleware → requestLogger → routes
@@ -114,14 +221,20 @@ func (r *Router) buildMiddlewareChain() {
 	//   - CorrelationID: preserved across retries and service hops
 	//   - RequestID: unique per HTTP transaction at the gateway
 	//
+	// Metrics sits next to RequestID so http_requests_total/duration
+	// observations happen for every request that reaches the mux,
+	// regardless of which route ultimately handles it.
+	//
 	// ArgoCD's gRPC logging interceptor (util-grpc/logging.go) attaches
 	// per-call structured fields in a similar innermost position.
 	var h http.Handler = r.mux
 	h = middlewares.CorrelationID(h)
+	h = metrics.Metrics(h)
 	h = middlewares.RequestID(h)
 	h = middlewares.RequestLog(h)
 	h = middlewares.SecurityHeaders(h)
 	h = middlewares.Recovery()(h)
+	h = otelhttp.NewHandler(h, "gateway-service", otelhttp.WithTracerProvider(r.tracerProvider))
 	r.handler = h
 }
 
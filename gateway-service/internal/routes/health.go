@@ -3,18 +3,14 @@ package routes
 import (
 	"gateway-service/internal/handlers"
 	"net/http"
-
-	pb "gateway-service/internal/gen/proto/go/vartrack/v1/services"
 )
 
-func HealthRoutes(conn handlers.GRPCConnChecker, client pb.OrchestratorClient) http.Handler {
-	h := handlers.NewHealthHandler(conn, client)
-
+// HealthRoutes mounts liveness/readiness on the given, already-constructed
+// HealthHandler — shared with the admin server's own health endpoints so
+// SetUnavailable/RegisterProbe affect both.
+func HealthRoutes(h *handlers.HealthHandler) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /liveness", h.Liveness)
 	mux.HandleFunc("GET /readiness", h.Readiness)
 	return mux
 }
-
-//livenessHandler := http.HandlerFunc(h.Liveness)
-//mux.Handle("GET /liveness", middlewares.SpecialCheck(livenessHandler))
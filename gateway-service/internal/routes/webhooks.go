@@ -1,19 +1,30 @@
 package routes
 
 import (
-	"gateway-service/internal/config"
 	"gateway-service/internal/handlers"
+	"gateway-service/internal/middlewares"
+	"gateway-service/internal/models"
 	"net/http"
+	"time"
 )
 
-// WebhookRoutes now accepts the PlatformRegistry as a dependency.
-// This allows the router to pass the explicitly wired registry down to the handler.
-func WebhookRoutes(platformService *config.PlatformService) http.Handler {
-    h := handlers.NewWebhookHandler(platformService)
-    mux := http.NewServeMux()
+// WebhookRoutes wires the webhook handler with the bundle, async delivery
+// queue, circuit breaker, rate limiter, and idempotency store the router
+// constructed it with, and registers the datasource and schema-registry
+// endpoints.
+func WebhookRoutes(
+	bundle *models.Bundle,
+	queue *handlers.WebhookQueue,
+	breaker *middlewares.CircuitBreaker,
+	limiter *middlewares.RateLimiter,
+	idempotency handlers.IdempotencyStore,
+	idempotencyTTL time.Duration,
+) http.Handler {
+	h := handlers.NewWebhookHandler(bundle, queue, breaker, limiter, idempotency, idempotencyTTL)
+	mux := http.NewServeMux()
 
-	// Matches only the root of this sub-router
-	mux.HandleFunc("GET /", h.Handle)
+	mux.HandleFunc("POST /{datasource}", h.Handle)
+	mux.HandleFunc("POST /schema-registry", h.HandleSchemaRegistry)
 
 	return mux
 
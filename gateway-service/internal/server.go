@@ -2,19 +2,23 @@ package internal
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/tls"
-	"crypto/x509"
-	"crypto/x509/pkix"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math/big"
 	"net"
 	"net/http"
 	"os"
 	"time"
+
+	"gateway-service/internal/certmanager"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/soheilhy/cmux"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
 )
 
 // TLSConfig holds inbound TLS settings for the HTTP server.
@@ -27,18 +31,77 @@ type TLSConfig struct {
 	CertFile string // GATEWAY_TLS_CERT — path to PEM-encoded certificate
 	KeyFile  string // GATEWAY_TLS_KEY  — path to PEM-encoded private key
 
+	// ClientCAFile is an optional PEM-encoded CA bundle trusted for
+	// inbound client certificates (mTLS from sidecars fronting the
+	// gateway). Only used when CertFile/KeyFile are also set — see
+	// buildServerTLSConfig, which wires CertFile/KeyFile/ClientCAFile
+	// into a certmanager.Manager so rotation on disk (cert-manager, a
+	// Vault agent) takes effect on the next handshake, with zero
+	// restart, the same way etcd's client transport rotates its Root CA.
+	ClientCAFile string
+
+	// RequireClientCert mandates a verified client certificate on every
+	// inbound connection (tls.RequireAndVerifyClientCert) instead of
+	// merely verifying one if presented (tls.VerifyClientCertIfGiven).
+	// Only meaningful alongside ClientCAFile.
+	RequireClientCert bool
+
 	// MinVersion and MaxVersion follow ArgoCD's tls util pattern which
 	// maps string versions ("1.2", "1.3") to crypto/tls constants and
 	// validates MinVersion <= MaxVersion. We use constants directly.
 	MinVersion uint16 // default: tls.VersionTLS12
 
-	// SelfSignedIfMissing mirrors ArgoCD's CreateServerTLSConfig():
-	// when true and cert/key files don't exist, the server generates
-	// a self-signed cert for the session. This is useful for local dev
-	// but should never be set in production.
-	SelfSignedIfMissing bool
+	// SelfSignedCert is a pre-built self-signed certificate used when no
+	// cert/key files are configured. Mirrors ArgoCD's
+	// CreateServerTLSConfig self-signed fallback, but the cert itself is
+	// generated (and persisted, so restarts don't invalidate a
+	// developer's browser trust) by cmd/main.go's resolveInboundTLS via
+	// LoadOrGenerateDevCA, not by buildServerTLSConfig — that way the same
+	// CA can also be handed to the admin server for /admin/ca.pem.
+	SelfSignedCert *tls.Certificate
+
+	// ACMEEnabled turns on automatic certificate acquisition from an ACME
+	// CA (e.g. Let's Encrypt) via golang.org/x/crypto/acme/autocert,
+	// instead of loading a fixed cert/key pair or a self-signed dev cert.
+	// Mirrors how Headscale wires autocert into its HTTP server. Takes
+	// priority over CertFile/KeyFile/SelfSignedCert when set.
+	ACMEEnabled bool
+
+	// ACMEEmail is passed to the CA as the account contact address for
+	// expiry/revocation notices.
+	ACMEEmail string
+
+	// ACMEHosts restricts certificate issuance to this allow-list via
+	// autocert.HostWhitelist, so a request for an arbitrary Host header
+	// can't trigger an issuance attempt against the CA's rate limit.
+	ACMEHosts []string
+
+	// ACMECacheDir persists issued certificates to disk (autocert.DirCache)
+	// so a restart doesn't re-request them from the CA.
+	ACMECacheDir string
+
+	// ACMEChallengeType selects HTTP-01 or TLS-ALPN-01. Defaults to
+	// ACMEChallengeTLSALPN01 when empty, since that needs no separate
+	// port-80 listener.
+	ACMEChallengeType ACMEChallengeType
 }
 
+// ACMEChallengeType selects which ACME challenge autocert uses to prove
+// domain ownership to the CA.
+type ACMEChallengeType string
+
+const (
+	// ACMEChallengeHTTP01 proves ownership by serving a token at
+	// http://<host>/.well-known/acme-challenge/<token> on port 80 — Run
+	// mounts autocert.Manager.HTTPHandler there when this is selected.
+	ACMEChallengeHTTP01 ACMEChallengeType = "http-01"
+
+	// ACMEChallengeTLSALPN01 proves ownership entirely within the TLS
+	// handshake itself (no port 80 needed) — autocert.Manager.TLSConfig's
+	// GetCertificate handles it automatically.
+	ACMEChallengeTLSALPN01 ACMEChallengeType = "tls-alpn-01"
+)
+
 // Enabled returns true when TLS should be used. Mirrors ArgoCD's
 //
 //	func (server *ArgoCDServer) useTLS() bool {
@@ -51,21 +114,89 @@ func (t *TLSConfig) Enabled() bool {
 	if t == nil {
 		return false
 	}
-	return (t.CertFile != "" && t.KeyFile != "") || t.SelfSignedIfMissing
+	return t.ACMEEnabled || (t.CertFile != "" && t.KeyFile != "") || t.SelfSignedCert != nil
+}
+
+// ProxyProtocolConfig configures PROXY protocol (v1/v2) support on the
+// inbound listener, for deployments sitting behind an L4 load balancer
+// (AWS NLB, HAProxy TCP mode) that can't terminate TLS or rewrite
+// X-Forwarded-For and instead prepends a PROXY header carrying the real
+// client address.
+//
+// Pattern: haproxy/proxyproto's own Listener wraps a net.Listener and
+// rewrites Accept() to return a conn whose RemoteAddr() is the proxied
+// address — every downstream consumer of r.RemoteAddr (Recovery's log
+// fields, rate limiting, webhook signature logging) sees the real client
+// IP with no further plumbing.
+type ProxyProtocolConfig struct {
+	// TrustedCIDRs lists the upstream networks allowed to send a PROXY
+	// header. A connection from any other source has its header rejected
+	// and the raw socket address is used instead, so an untrusted peer
+	// can't spoof its IP by prepending its own PROXY line.
+	TrustedCIDRs []string
+}
+
+// Enabled returns true when the listener should be wrapped.
+func (p *ProxyProtocolConfig) Enabled() bool {
+	return p != nil
+}
+
+// policy builds the proxyproto.PolicyFunc that enforces TrustedCIDRs.
+// Mirrors go-proxyproto's own SkipProxyHeaderForCIDR helper, but inverted
+// into an allow-list: REJECT (rather than USE) is returned for upstreams
+// that live outside every trusted CIDR, and go-proxyproto surfaces that
+// as a hard error to the accept loop instead of silently trusting the
+// header or falling back to the raw address.
+func (p *ProxyProtocolConfig) policy() proxyproto.PolicyFunc {
+	nets := make([]*net.IPNet, 0, len(p.TrustedCIDRs))
+	for _, cidr := range p.TrustedCIDRs {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+
+	return func(upstream net.Addr) (proxyproto.Policy, error) {
+		host, _, err := net.SplitHostPort(upstream.String())
+		if err != nil {
+			return proxyproto.REJECT, fmt.Errorf("proxy protocol: invalid upstream address %q: %w", upstream, err)
+		}
+		ip := net.ParseIP(host)
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return proxyproto.USE, nil
+			}
+		}
+		return proxyproto.REJECT, fmt.Errorf("proxy protocol: upstream %s is not in a trusted CIDR", host)
+	}
 }
 
 // Run starts the HTTP server and blocks until ctx is cancelled.
 //
 // If tlsCfg is non-nil and enabled, the server terminates TLS itself.
 // Otherwise it serves plaintext, expecting TLS termination upstream
-// (Ingress, ALB, sidecar proxy).
-func Run(ctx context.Context, addr string, handler http.Handler, tlsCfg *TLSConfig) {
-	// Pre-flight port check — Bytebase's checkPort() pattern.
-	if err := checkPort(addr); err != nil {
+// (Ingress, ALB, sidecar proxy). If proxyCfg is non-nil, the raw listener
+// is wrapped with a PROXY protocol decoder before TLS/HTTP ever see it.
+//
+// If grpcServer is non-nil, it is multiplexed onto the same listener as
+// handler via runMultiplexed — the same single-port split Headscale's
+// app.go uses between its gRPC and HTTP/REST servers. grpcServer is nil
+// until the gateway registers its own gRPC service; until then Run serves
+// HTTP alone on addr, exactly as before.
+func Run(ctx context.Context, addr string, handler http.Handler, grpcServer *grpc.Server, tlsCfg *TLSConfig, proxyCfg *ProxyProtocolConfig) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
 		slog.Error("port not available", "addr", addr, "error", err)
 		os.Exit(1)
 	}
 
+	if proxyCfg.Enabled() {
+		slog.Info("proxy protocol: enabled", "trusted_cidrs", proxyCfg.TrustedCIDRs)
+		ln = &proxyproto.Listener{
+			Listener: ln,
+			Policy:   proxyCfg.policy(),
+		}
+	}
+
 	srv := &http.Server{
 		Addr:              addr,
 		Handler:           handler,
@@ -78,12 +209,29 @@ func Run(ctx context.Context, addr string, handler http.Handler, tlsCfg *TLSConf
 	useTLS := tlsCfg != nil && tlsCfg.Enabled()
 
 	if useTLS {
-		tlsServerConfig, err := buildServerTLSConfig(tlsCfg)
-		if err != nil {
-			slog.Error("failed to build TLS config", "error", err)
-			os.Exit(1)
+		if tlsCfg.ACMEEnabled {
+			acmeManager := buildACMEManager(tlsCfg)
+			srv.TLSConfig = acmeManager.TLSConfig()
+			if tlsCfg.MinVersion != 0 {
+				srv.TLSConfig.MinVersion = tlsCfg.MinVersion
+			}
+
+			if tlsCfg.ACMEChallengeType == ACMEChallengeHTTP01 {
+				go serveACMEHTTPChallenge(acmeManager)
+			}
+		} else {
+			tlsServerConfig, err := buildServerTLSConfig(tlsCfg)
+			if err != nil {
+				slog.Error("failed to build TLS config", "error", err)
+				os.Exit(1)
+			}
+			srv.TLSConfig = tlsServerConfig
 		}
-		srv.TLSConfig = tlsServerConfig
+	}
+
+	if grpcServer != nil {
+		runMultiplexed(ctx, addr, ln, srv, grpcServer, useTLS, proxyCfg)
+		return
 	}
 
 	// Graceful shutdown — ArgoCD's shutdownCtx with 20s timeout.
@@ -97,16 +245,18 @@ func Run(ctx context.Context, addr string, handler http.Handler, tlsCfg *TLSConf
 		}
 	}()
 
-	slog.Info("server starting", "addr", addr, "tls", useTLS)
+	slog.Info("server starting", "addr", addr, "tls", useTLS, "proxy_protocol", proxyCfg.Enabled())
 
-	var err error
 	if useTLS {
-		// When TLSConfig is set on the server, ListenAndServeTLS with
-		// empty strings uses the config's Certificates / GetCertificate.
-		// When files are provided, they're loaded into the config.
-		err = srv.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+		// Passing non-empty filenames here makes ServeTLS call
+		// tls.LoadX509KeyPair itself and overwrite whatever Certificates/
+		// GetCertificate srv.TLSConfig already has — which would bypass
+		// certmanager's hot-reload and the ACME/self-signed paths below
+		// it. Every mode populates srv.TLSConfig fully upfront, so empty
+		// strings here just mean "use what's already there".
+		err = srv.ServeTLS(ln, "", "")
 	} else {
-		err = srv.ListenAndServe()
+		err = srv.Serve(ln)
 	}
 
 	// ArgoCD's checkServeErr: only log ErrServerClosed as info.
@@ -120,6 +270,109 @@ func Run(ctx context.Context, addr string, handler http.Handler, tlsCfg *TLSConf
 	}
 }
 
+// runMultiplexed serves handler (wrapped for h2c so plaintext HTTP/2
+// reaches it too) and grpcServer on the same listener ln via cmux, the
+// way Headscale's app.go splits its gRPC and HTTP servers across one
+// port: a cmux in front matches each accepted connection by its leading
+// bytes and routes it to the right sub-listener before either server's
+// Serve loop ever sees it.
+//
+// Matching order matters — gRPC always negotiates HTTP/2 with the
+// "application/grpc" content-type, so that matcher runs first and claims
+// every gRPC connection; cmux.Any() then catches everything left over
+// (HTTP/1.1, and plaintext HTTP/2 via h2c) for the HTTP server.
+//
+// Shutdown orders as: stop accepting new connections (close the cmux
+// listener), drain in-flight gRPC calls (GracefulStop), then drain
+// in-flight HTTP requests (srv.Shutdown) — so a gRPC call already in
+// progress isn't cut off by the HTTP server's shutdown timer, and no new
+// connection of either kind can slip in while draining.
+func runMultiplexed(ctx context.Context, addr string, ln net.Listener, srv *http.Server, grpcServer *grpc.Server, useTLS bool, proxyCfg *ProxyProtocolConfig) {
+	if useTLS {
+		ln = tls.NewListener(ln, srv.TLSConfig)
+	}
+
+	h2s := &http2.Server{}
+	srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+
+	m := cmux.New(ln)
+	grpcL := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+	httpL := m.Match(cmux.Any())
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("shutting down multiplexed server")
+		_ = ln.Close()
+		grpcServer.GracefulStop()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("server shutdown error", "error", err)
+		}
+	}()
+
+	go func() {
+		if err := grpcServer.Serve(grpcL); err != nil && !isClosedListenerErr(err) {
+			slog.Error("grpc server error", "error", err)
+		}
+	}()
+
+	go func() {
+		if err := srv.Serve(httpL); err != nil && err != http.ErrServerClosed && !isClosedListenerErr(err) {
+			slog.Error("server error", "error", err)
+		}
+	}()
+
+	slog.Info("server starting (multiplexed http+grpc)", "addr", addr, "tls", useTLS, "proxy_protocol", proxyCfg.Enabled())
+
+	if err := m.Serve(); err != nil && !isClosedListenerErr(err) {
+		slog.Error("cmux error", "error", err)
+	}
+}
+
+// isClosedListenerErr reports whether err is the expected result of
+// closing a listener mid-Accept during graceful shutdown, as opposed to a
+// genuine serve error.
+func isClosedListenerErr(err error) bool {
+	return errors.Is(err, cmux.ErrListenerClosed) || errors.Is(err, net.ErrClosed)
+}
+
+// buildACMEManager constructs an autocert.Manager from cfg's ACME*
+// fields. Run installs its TLSConfig() as the server's certificate source
+// and, for ACMEChallengeHTTP01, starts the HTTP-01 challenge handler on
+// :80 — the same split Headscale uses between its HTTPS listener and a
+// dedicated plaintext challenge listener.
+func buildACMEManager(cfg *TLSConfig) *autocert.Manager {
+	var cache autocert.Cache
+	if cfg.ACMECacheDir != "" {
+		cache = autocert.DirCache(cfg.ACMECacheDir)
+	}
+
+	m := &autocert.Manager{
+		Prompt: autocert.AcceptTOS,
+		Email:  cfg.ACMEEmail,
+		Cache:  cache,
+	}
+	if len(cfg.ACMEHosts) > 0 {
+		m.HostPolicy = autocert.HostWhitelist(cfg.ACMEHosts...)
+	}
+
+	slog.Info("inbound TLS: ACME certificate acquisition enabled",
+		"hosts", cfg.ACMEHosts, "challenge", cfg.ACMEChallengeType, "cache_dir", cfg.ACMECacheDir)
+	return m
+}
+
+// serveACMEHTTPChallenge runs autocert's HTTP-01 challenge handler on :80
+// until it exits. Run starts this in its own goroutine — a failure here
+// only blocks certificate renewal, not the HTTPS listener already serving
+// whatever cert autocert last obtained.
+func serveACMEHTTPChallenge(m *autocert.Manager) {
+	slog.Info("ACME: HTTP-01 challenge handler starting", "addr", ":80")
+	if err := http.ListenAndServe(":80", m.HTTPHandler(nil)); err != nil {
+		slog.Error("ACME: HTTP-01 challenge handler error", "error", err)
+	}
+}
+
 // buildServerTLSConfig constructs a *tls.Config for the HTTP server.
 //
 // Pattern sources:
@@ -127,12 +380,14 @@ func Run(ctx context.Context, addr string, handler http.Handler, tlsCfg *TLSConf
 //     to self-signed when files are missing and the option is set.
 //   - ArgoCD's tls util: MinVersion default TLS 1.2, cipher suite parsing.
 //   - Jaeger's tlscfg.options: structured config with MinVersion/MaxVersion.
+//   - etcd's client transport: rotates its Root CA/cert without a restart
+//     by re-reading it per handshake instead of loading it once at dial
+//     time — mirrored here via certmanager.Manager for the cert/key/
+//     ClientCAFile trio.
 func buildServerTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
-	tc := &tls.Config{
-		MinVersion: cfg.MinVersion,
-	}
-	if tc.MinVersion == 0 {
-		tc.MinVersion = tls.VersionTLS12 // ArgoCD's DefaultTLSMinVersion
+	minVersion := cfg.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12 // ArgoCD's DefaultTLSMinVersion
 	}
 
 	hasCert := cfg.CertFile != ""
@@ -140,78 +395,35 @@ func buildServerTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
 
 	switch {
 	case hasCert && hasKey:
-		// Load from files — matches ArgoCD's CreateServerTLSConfig
-		// "Loading TLS configuration from cert=%s and key=%s" path.
-		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		// Hot-reloadable — certmanager.Manager watches CertFile/KeyFile
+		// (and ClientCAFile, if set) via fsnotify and atomically
+		// republishes the parsed pair on change, so GetCertificate/
+		// GetConfigForClient always serve the latest rotation on the
+		// next handshake without restarting the listener. This is the
+		// "server cert used by an inbound listener" use case
+		// certmanager's package doc has called out since it was
+		// written — previously only the outbound gRPC client used it.
+		mgr, err := certmanager.New(cfg.ClientCAFile, cfg.CertFile, cfg.KeyFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load TLS keypair (cert=%s, key=%s): %w",
+			return nil, fmt.Errorf("failed to start hot-reloadable TLS cert manager (cert=%s, key=%s): %w",
 				cfg.CertFile, cfg.KeyFile, err)
 		}
-		tc.Certificates = []tls.Certificate{cert}
-		slog.Info("loaded TLS certificate from files",
-			"cert", cfg.CertFile, "key", cfg.KeyFile)
-
-	case cfg.SelfSignedIfMissing:
-		// Generate self-signed cert for the session — mirrors ArgoCD's
-		// CreateServerTLSConfig which calls GenerateX509KeyPair when
-		// cert files are not found, logging:
-		//   "Generating self-signed TLS certificate for this session"
-		cert, err := generateSelfSignedCert()
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate self-signed cert: %w", err)
-		}
-		tc.Certificates = []tls.Certificate{cert}
-		slog.Warn("using auto-generated self-signed TLS certificate (not for production)")
+		tc := mgr.GetHTTPServerTLSConfig(cfg.RequireClientCert)
+		tc.MinVersion = minVersion
+		slog.Info("inbound TLS: hot-reloadable certificate (cert-manager/Vault rotation)",
+			"cert", cfg.CertFile, "key", cfg.KeyFile,
+			"client_ca", cfg.ClientCAFile, "require_client_cert", cfg.RequireClientCert)
+		return tc, nil
+
+	case cfg.SelfSignedCert != nil:
+		// Pre-built by LoadOrGenerateDevCA — mirrors ArgoCD's
+		// CreateServerTLSConfig fallback, but generation/persistence
+		// already happened in cmd/main.go's resolveInboundTLS.
+		tc := &tls.Config{MinVersion: minVersion, Certificates: []tls.Certificate{*cfg.SelfSignedCert}}
+		slog.Warn("using self-signed dev TLS certificate (not for production)")
+		return tc, nil
 
 	default:
 		return nil, fmt.Errorf("TLS enabled but no cert/key provided and self-signed fallback is disabled")
 	}
-
-	return tc, nil
-}
-
-// generateSelfSignedCert creates a self-signed ECDSA P-256 certificate
-// valid for localhost and 127.0.0.1, lasting 24 hours. Inspired by
-// ArgoCD's GenerateX509KeyPair in util/tls/tls.go which generates
-// self-signed certs with configurable hosts, organization, and validity.
-func generateSelfSignedCert() (tls.Certificate, error) {
-	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-
-	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-
-	template := x509.Certificate{
-		SerialNumber: serial,
-		Subject:      pkix.Name{Organization: []string{"gateway-service (self-signed)"}},
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().Add(24 * time.Hour),
-		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		DNSNames:     []string{"localhost"},
-		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
-	}
-
-	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-
-	return tls.Certificate{
-		Certificate: [][]byte{certDER},
-		PrivateKey:  key,
-	}, nil
-}
-
-// checkPort verifies the address is available — Bytebase's checkPort pattern.
-func checkPort(addr string) error {
-	ln, err := net.Listen("tcp", addr)
-	if err != nil {
-		return err
-	}
-	return ln.Close()
 }
@@ -0,0 +1,103 @@
+// Package retry wraps cenkalti/backoff/v4 with the classify-then-retry
+// shape this module needs for its two transient-failure-prone I/O paths:
+// Vault (models/secret_managers) and the SCM platform drivers
+// (models/platform). Traefik's providers lean on the same library for
+// resilient bootstrapping against a backend that may not be up yet.
+package retry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// Policy configures the exponential-backoff schedule and the Classify
+// func that decides whether a given error is even worth retrying.
+type Policy struct {
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+
+	// Classify reports whether err looks transient and should be
+	// retried. A nil Classify falls back to DefaultClassifier.
+	Classify func(err error) bool
+}
+
+// DefaultPolicy is a conservative schedule suitable for both Vault calls
+// at pod startup and outbound SCM API calls — short enough that a
+// webhook request doesn't hang for minutes, long enough to ride out a
+// brief control-plane blip.
+func DefaultPolicy() Policy {
+	return Policy{
+		InitialInterval: 250 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     5 * time.Second,
+		MaxElapsedTime:  30 * time.Second,
+	}
+}
+
+// DefaultClassifier retries context.DeadlineExceeded, net.Error timeouts,
+// Vault's 429 (rate limited) and 503 (sealed/unavailable) responses, and
+// any other 5xx. It does not retry 4xx auth failures — a bad token or
+// role isn't going to start working because we waited.
+func DefaultClassifier(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var respErr *vault.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == 429 || respErr.StatusCode >= 500
+	}
+
+	var coder interface{ StatusCode() int }
+	if errors.As(err, &coder) {
+		code := coder.StatusCode()
+		return code == 429 || code >= 500
+	}
+
+	return false
+}
+
+// Do runs fn, retrying per policy's backoff schedule as long as
+// policy.Classify (or DefaultClassifier, if nil) says the returned error
+// is transient. It gives up and returns the last error once ctx is
+// cancelled, MaxElapsedTime elapses, or fn returns a non-transient error.
+func Do(ctx context.Context, policy Policy, fn func() error) error {
+	classify := policy.Classify
+	if classify == nil {
+		classify = DefaultClassifier
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = policy.InitialInterval
+	bo.Multiplier = policy.Multiplier
+	bo.MaxInterval = policy.MaxInterval
+	bo.MaxElapsedTime = policy.MaxElapsedTime
+	bo.Reset()
+
+	return backoff.Retry(func() error {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !classify(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(bo, ctx))
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cachedSecret holds a resolved secret value plus the time it stops
+// being valid.
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// CachingSecretManager wraps a SecretManager with an in-process TTL cache
+// in front of GetSecret, so hot-path webhook handling doesn't round-trip
+// to Vault/AWS/GCP/file on every call. An entry older than ttl is treated
+// as a miss and re-fetched from the wrapped manager.
+type CachingSecretManager struct {
+	SecretManager
+	ttl time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedSecret
+}
+
+// NewCachingSecretManager wraps sm with a TTL cache. A ttl <= 0 disables
+// caching — every call passes straight through to sm.
+func NewCachingSecretManager(sm SecretManager, ttl time.Duration) *CachingSecretManager {
+	return &CachingSecretManager{
+		SecretManager: sm,
+		ttl:           ttl,
+		cache:         make(map[string]cachedSecret),
+	}
+}
+
+func secretCacheKey(path, key string) string {
+	return path + "\x00" + key
+}
+
+func (c *CachingSecretManager) GetSecret(ctx context.Context, path string, key string) (string, error) {
+	if c.ttl <= 0 {
+		return c.SecretManager.GetSecret(ctx, path, key)
+	}
+
+	cacheKey := secretCacheKey(path, key)
+
+	c.mu.RLock()
+	entry, ok := c.cache[cacheKey]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := c.SecretManager.GetSecret(ctx, path, key)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.cache[cacheKey] = cachedSecret{value: value, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// Purge drops every cached entry, forcing the next GetSecret to hit the
+// wrapped manager. Nothing in this module calls it, so a config reload
+// leaves cached values in place until their ttl expires.
+func (c *CachingSecretManager) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache = make(map[string]cachedSecret)
+}
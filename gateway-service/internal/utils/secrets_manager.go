@@ -5,8 +5,13 @@ import (
 	"fmt"
 	pb_models "gateway-service/internal/gen/proto/go/vartrack/v1/models"
 	"sync"
+	"time"
 )
 
+// DefaultSecretCacheTTL is the TTL applied to a secret manager's GetSecret
+// cache when its config doesn't set one explicitly.
+const DefaultSecretCacheTTL = 5 * time.Minute
+
 // SecretManager defines the contract for secret management backends.
 type SecretManager interface {
 	// Open initializes the secret manager from protobuf config.
@@ -80,11 +85,35 @@ func GetSecretManagerName(sm *pb_models.SecretManager) string {
 	switch config := sm.Config.(type) {
 	case *pb_models.SecretManager_Vault:
 		return config.Vault.Name
-	// case *pb_models.SecretManager_Gcp:
-	//     return config.Gcp.Name
-	// case *pb_models.SecretManager_Aws:
-	//     return config.Aws.Name
+	case *pb_models.SecretManager_Aws:
+		return ResolveTagName("aws", config.Aws.GetTag())
+	case *pb_models.SecretManager_Gcp:
+		return ResolveTagName("gcp", config.Gcp.GetTag())
+	case *pb_models.SecretManager_File:
+		return ResolveTagName("file", config.File.GetTag())
 	default:
 		return ""
 	}
+}
+
+// SecretManagerCacheTTL resolves the GetSecret cache TTL configured for sm,
+// falling back to DefaultSecretCacheTTL when the driver's config doesn't
+// set CacheTtlSeconds (or sets it to <= 0).
+func SecretManagerCacheTTL(sm *pb_models.SecretManager) time.Duration {
+	var seconds int32
+	switch config := sm.Config.(type) {
+	case *pb_models.SecretManager_Vault:
+		seconds = config.Vault.GetCacheTtlSeconds()
+	case *pb_models.SecretManager_Aws:
+		seconds = config.Aws.GetCacheTtlSeconds()
+	case *pb_models.SecretManager_Gcp:
+		seconds = config.Gcp.GetCacheTtlSeconds()
+	case *pb_models.SecretManager_File:
+		seconds = config.File.GetCacheTtlSeconds()
+	}
+
+	if seconds <= 0 {
+		return DefaultSecretCacheTTL
+	}
+	return time.Duration(seconds) * time.Second
 }
\ No newline at end of file